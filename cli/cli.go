@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+type (
+	// Invoker calls passed function with dependencies from DI container.
+	Invoker interface {
+		Invoke(fn interface{}, opts ...dig.InvokeOption) error
+	}
+
+	// Builder creates DI container for the command, flags of the command are passed into it,
+	// so they can be bound into settings (see Bind).
+	Builder func(*pflag.FlagSet) (Invoker, error)
+
+	// Command of the application, it can contain subcommands.
+	Command struct {
+		Name  string
+		Usage string
+
+		// Flags allows to declare flags of the command, they are bound into settings by Bind.
+		Flags func(*pflag.FlagSet)
+
+		// Run is called when command is selected, command without Run prints its usage.
+		Run func(*Context) error
+
+		Commands []*Command
+	}
+
+	// App is the root of the commands tree.
+	App struct {
+		Name   string
+		Output io.Writer
+
+		// Default is the name of the command, that is called when command is not passed.
+		Default string
+
+		// Flags allows to declare global flags of the application.
+		Flags func(*pflag.FlagSet)
+
+		Builder  Builder
+		Commands []*Command
+	}
+
+	// Context of the called command.
+	Context struct {
+		Args   []string
+		Flags  *pflag.FlagSet
+		Output io.Writer
+
+		build   Builder
+		invoker Invoker
+	}
+)
+
+const (
+	// ErrUnknownCommand is raised when passed command was not found.
+	ErrUnknownCommand = internal.Error("unknown command")
+
+	// ErrEmptyBuilder is raised when command invokes dependencies, but builder is not set.
+	ErrEmptyBuilder = internal.Error("empty builder")
+
+	// SkipBind is the annotation of flags, that should not be bound into settings.
+	SkipBind = "helium_skip_bind"
+)
+
+// Invoke calls passed function with dependencies from DI container.
+// DI container is created on the first call.
+func (c *Context) Invoke(fn interface{}, opts ...dig.InvokeOption) error {
+	invoker, err := c.Container()
+	if err != nil {
+		return err
+	}
+
+	return invoker.Invoke(fn, opts...)
+}
+
+// Container returns DI container of the command, it is created on the first call.
+func (c *Context) Container() (Invoker, error) {
+	if c.invoker != nil {
+		return c.invoker, nil
+	}
+
+	if c.build == nil {
+		return nil, ErrEmptyBuilder
+	}
+
+	invoker, err := c.build(c.Flags)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invoker = invoker
+
+	return invoker, nil
+}
+
+// Execute finds the command by passed arguments (without program name), parses flags and runs the command.
+func (a *App) Execute(args []string) error {
+	out := a.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	path, depth, err := a.lookup(args)
+	if err != nil {
+		a.usage(out, path)
+
+		return err
+	}
+
+	flags := a.flagSet(path, false)
+	if err = flags.Parse(args); errors.Is(err, pflag.ErrHelp) {
+		// default command is not shown, help of the application is printed
+		a.usage(out, path[:depth+1])
+
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	cmd := path[len(path)-1]
+	if cmd.Run == nil {
+		a.usage(out, path)
+
+		return nil
+	}
+
+	return cmd.Run(&Context{
+		Args:   positional(flags.Args(), depth),
+		Flags:  flags,
+		Output: out,
+		build:  a.Builder,
+	})
+}
+
+// lookup returns path to the command, first element is the root of the tree,
+// and the count of commands names that were passed in arguments.
+// Flags of the command should be passed after its name.
+func (a *App) lookup(args []string) ([]*Command, int, error) {
+	path := []*Command{{Name: a.Name, Commands: a.Commands}}
+
+	for {
+		flags := a.flagSet(path, true)
+		_ = flags.Parse(args) // nolint:errcheck // errors will be reported by strict parsing
+
+		rest := positional(flags.Args(), len(path)-1)
+		if len(rest) == 0 {
+			break
+		}
+
+		next := find(path[len(path)-1].Commands, rest[0])
+		if next == nil {
+			if len(path) > 1 || len(a.Commands) == 0 {
+				break
+			}
+
+			return path, 0, fmt.Errorf("%w: %q", ErrUnknownCommand, rest[0])
+		}
+
+		path = append(path, next)
+	}
+
+	depth := len(path) - 1
+	if depth == 0 && a.Default != "" {
+		if cmd := find(a.Commands, a.Default); cmd != nil {
+			path = append(path, cmd)
+		}
+	}
+
+	return path, depth, nil
+}
+
+// flagSet creates flags of the application and flags of the last command of the path.
+func (a *App) flagSet(path []*Command, lax bool) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(a.Name, pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.ParseErrorsWhitelist.UnknownFlags = lax
+
+	if a.Flags != nil {
+		a.Flags(flags)
+	}
+
+	if cmd := path[len(path)-1]; cmd.Flags != nil {
+		cmd.Flags(flags)
+	}
+
+	return flags
+}
+
+// usage prints usage of the last command of the path.
+func (a *App) usage(out io.Writer, path []*Command) {
+	names := make([]string, 0, len(path))
+	for _, cmd := range path {
+		names = append(names, cmd.Name)
+	}
+
+	cmd := path[len(path)-1]
+
+	fmt.Fprintf(out, "Usage: %s [flags]", strings.Join(names, " "))
+
+	if len(cmd.Commands) > 0 {
+		fmt.Fprint(out, " [command]")
+	}
+
+	fmt.Fprintln(out)
+
+	if cmd.Usage != "" {
+		fmt.Fprintf(out, "\n%s\n", cmd.Usage)
+	}
+
+	if len(cmd.Commands) > 0 {
+		fmt.Fprintln(out, "\nCommands:")
+
+		list := append([]*Command(nil), cmd.Commands...)
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+		tab := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, sub := range list {
+			fmt.Fprintf(tab, "  %s\t%s\n", sub.Name, sub.Usage)
+		}
+
+		_ = tab.Flush() // nolint:errcheck
+	}
+
+	fmt.Fprintf(out, "\nFlags:\n%s  -h, --help   show help\n", a.flagSet(path, false).FlagUsages())
+}
+
+// Bind binds flags into settings, flags with SkipBind annotation are ignored.
+func Bind(v *viper.Viper, flags *pflag.FlagSet) error {
+	var err error
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if _, skip := flag.Annotations[SkipBind]; skip || err != nil {
+			return
+		}
+
+		err = v.BindPFlag(flag.Name, flag)
+	})
+
+	return err
+}
+
+// positional returns arguments without names of commands.
+func positional(args []string, skip int) []string {
+	if len(args) >= skip {
+		return args[skip:]
+	}
+
+	return nil
+}
+
+func find(list []*Command, name string) *Command {
+	for _, cmd := range list {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
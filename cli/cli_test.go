@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+)
+
+func TestApp(t *testing.T) {
+	var (
+		called string
+		args   []string
+		value  string
+	)
+
+	record := func(name string) func(*Context) error {
+		return func(ctx *Context) error {
+			called, args = name, ctx.Args
+
+			return nil
+		}
+	}
+
+	prepare := func(out *bytes.Buffer) *App {
+		called, args, value = "", nil, ""
+
+		return &App{
+			Name:    "app",
+			Output:  out,
+			Default: "run",
+			Flags: func(flags *pflag.FlagSet) {
+				flags.StringVar(&value, "global", "", "global flag")
+			},
+			Commands: []*Command{
+				{Name: "run", Usage: "run the app", Run: record("run")},
+				{Name: "config", Usage: "settings", Commands: []*Command{
+					{Name: "print", Run: record("config print"), Flags: func(flags *pflag.FlagSet) {
+						flags.String("format", "yaml", "output format")
+					}},
+				}},
+			},
+		}
+	}
+
+	t.Run("should run default command", func(t *testing.T) {
+		require.NoError(t, prepare(new(bytes.Buffer)).Execute([]string{"--global", "value"}))
+		require.Equal(t, "run", called)
+		require.Empty(t, args)
+		require.Equal(t, "value", value)
+	})
+
+	t.Run("should run nested command with flags and arguments", func(t *testing.T) {
+		require.NoError(t, prepare(new(bytes.Buffer)).Execute([]string{
+			"--global=value", "config", "print", "--format", "json", "first", "second",
+		}))
+		require.Equal(t, "config print", called)
+		require.Equal(t, []string{"first", "second"}, args)
+		require.Equal(t, "value", value)
+	})
+
+	t.Run("should print usage of the command without run", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, prepare(out).Execute([]string{"config"}))
+		require.Empty(t, called)
+		require.Contains(t, out.String(), "Usage: app config [flags] [command]")
+		require.Contains(t, out.String(), "print")
+	})
+
+	t.Run("should print help", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, prepare(out).Execute([]string{"--help"}))
+		require.Empty(t, called)
+		require.Contains(t, out.String(), "run the app")
+		require.Contains(t, out.String(), "--global")
+	})
+
+	t.Run("should fail on unknown command", func(t *testing.T) {
+		require.ErrorIs(t, prepare(new(bytes.Buffer)).Execute([]string{"unknown"}), ErrUnknownCommand)
+	})
+
+	t.Run("should fail on unknown flag", func(t *testing.T) {
+		require.Error(t, prepare(new(bytes.Buffer)).Execute([]string{"run", "--unknown"}))
+	})
+}
+
+func TestContext(t *testing.T) {
+	t.Run("should fail without builder", func(t *testing.T) {
+		ctx := &Context{}
+		require.ErrorIs(t, ctx.Invoke(func() {}), ErrEmptyBuilder)
+	})
+
+	t.Run("should build container once", func(t *testing.T) {
+		builds := 0
+		ctx := &Context{build: func(*pflag.FlagSet) (Invoker, error) {
+			builds++
+
+			di := dig.New()
+
+			return di, di.Provide(func() int { return 42 })
+		}}
+
+		require.NoError(t, ctx.Invoke(func(v int) { require.Equal(t, 42, v) }))
+		require.NoError(t, ctx.Invoke(func(int) {}))
+		require.Equal(t, 1, builds)
+	})
+}
+
+func TestBind(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("ops.address", ":8081", "ops address")
+	flags.String("skipped", "value", "skipped flag")
+	require.NoError(t, flags.SetAnnotation("skipped", SkipBind, []string{"true"}))
+	require.NoError(t, flags.Parse([]string{"--ops.address", ":9090"}))
+
+	v := viper.New()
+	require.NoError(t, Bind(v, flags))
+	require.Equal(t, ":9090", v.GetString("ops.address"))
+	require.False(t, v.IsSet("skipped"))
+}
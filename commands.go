@@ -0,0 +1,265 @@
+package helium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
+	"gopkg.in/yaml.v3"
+
+	"github.com/im-kulikov/helium/cli"
+	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/settings"
+)
+
+type (
+	// printParams allows to redact secrets when they are provided.
+	printParams struct {
+		dig.In
+
+		Viper   *viper.Viper
+		Secrets *settings.Secrets `optional:"true"`
+	}
+)
+
+const (
+	// ErrUnknownFormat is raised when settings could not be printed in passed format.
+	ErrUnknownFormat = internal.Error("unknown format")
+
+	// ErrUnknownContainer is raised when command is called with unknown DI container.
+	ErrUnknownContainer = internal.Error("unknown container")
+
+	flagConfig     = "config"
+	flagConfigType = "config-type"
+	flagFormat     = "format"
+	flagInvoke     = "invoke"
+	flagGraph      = "graph"
+
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+// Execute parses command line arguments (without program name) and runs the command:
+// - run (default) runs the application, `--graph` prints the dependency graph of the application instead.
+// - version prints name and version of the application.
+// - config print prints settings of the application, secrets are redacted.
+// - config validate checks that settings could be loaded and calls config validators.
+// - check checks that all dependencies could be resolved and config is valid (see Helium.Validate).
+// - graph prints the dependency graph in DOT or JSON format (see Helium.Graph).
+// Custom commands (see Settings.Commands) get dependencies from DI container by cli.Context.Invoke.
+// Flags of commands are bound into settings, `--config` and `--config-type` override config file.
+func Execute(cfg *Settings, args []string, mod ...module.Module) error {
+	if cfg == nil {
+		cfg = &Settings{}
+	}
+
+	app := &cli.App{
+		Name:    cfg.Name,
+		Output:  cfg.Output,
+		Default: "run",
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringP(flagConfig, "c", cfg.File, "path to config file")
+			flags.String(flagConfigType, cfg.Type, "type of config file")
+
+			_ = flags.SetAnnotation(flagConfig, cli.SkipBind, []string{"true"})     // nolint:errcheck
+			_ = flags.SetAnnotation(flagConfigType, cli.SkipBind, []string{"true"}) // nolint:errcheck
+		},
+		Builder: builder(cfg, mod...),
+		Commands: append([]*cli.Command{
+			{Name: "run", Usage: "run the application", Run: runCommand, Flags: runFlags},
+			{Name: "version", Usage: "print version of the application", Run: versionCommand(cfg)},
+			{Name: "check", Usage: "check dependencies and config without running", Run: checkCommand},
+			{Name: "graph", Usage: "print dependency graph of the application", Run: graphCommand, Flags: graphFlags},
+			{Name: "config", Usage: "work with settings of the application", Commands: []*cli.Command{
+				{Name: "print", Usage: "print settings of the application", Run: printCommand, Flags: formatFlag},
+				{Name: "validate", Usage: "check settings of the application", Run: validateCommand},
+			}},
+		}, cfg.Commands...),
+	}
+
+	return app.Execute(args)
+}
+
+// builder creates helium instance, config file could be overridden by flags and flags are bound into settings.
+func builder(cfg *Settings, mod ...module.Module) cli.Builder {
+	return func(flags *pflag.FlagSet) (cli.Invoker, error) {
+		if flags.Changed(flagConfig) {
+			cfg.File, _ = flags.GetString(flagConfig) // nolint:errcheck // flag exists
+		}
+
+		if flags.Changed(flagConfigType) {
+			cfg.Type, _ = flags.GetString(flagConfigType) // nolint:errcheck // flag exists
+		}
+
+		// flags are bound when settings are loaded, so they are used by conditions of modules
+		cfg.flags = flags
+
+		h, err := New(cfg, mod...)
+		if err != nil {
+			return nil, err
+		}
+
+		return h, nil
+	}
+}
+
+func formatFlag(flags *pflag.FlagSet) {
+	flags.StringP(flagFormat, "f", formatYAML, "output format (yaml or json)")
+
+	_ = flags.SetAnnotation(flagFormat, cli.SkipBind, []string{"true"}) // nolint:errcheck
+}
+
+// runFlags allows to print the graph by `run --graph` (in DOT format) or `run --graph=json`.
+func runFlags(flags *pflag.FlagSet) {
+	flags.String(flagGraph, "", "construct the application (without running) and print dependency graph (dot or json)")
+	flags.Lookup(flagGraph).NoOptDefVal = module.GraphDOT
+
+	_ = flags.SetAnnotation(flagGraph, cli.SkipBind, []string{"true"}) // nolint:errcheck
+}
+
+func graphFlags(flags *pflag.FlagSet) {
+	flags.StringP(flagFormat, "f", module.GraphDOT, "output format (dot or json)")
+	flags.Bool(flagInvoke, false, "construct the application (without running) to mark used constructors")
+
+	_ = flags.SetAnnotation(flagFormat, cli.SkipBind, []string{"true"}) // nolint:errcheck
+	_ = flags.SetAnnotation(flagInvoke, cli.SkipBind, []string{"true"}) // nolint:errcheck
+}
+
+func runCommand(ctx *cli.Context) error {
+	format, err := ctx.Flags.GetString(flagGraph)
+	if err != nil {
+		return err
+	}
+
+	if format == "" {
+		return ctx.Invoke(runApp)
+	}
+
+	return printGraph(ctx, format, true)
+}
+
+func versionCommand(cfg *Settings) func(*cli.Context) error {
+	return func(ctx *cli.Context) error {
+		build := settings.NewBuildInfo(&settings.Core{Name: cfg.Name, BuildVersion: cfg.BuildVersion, BuildTime: cfg.BuildTime})
+		_, err := fmt.Fprintf(ctx.Output, "%s %s (build time: %s)\n", build.Name, build.Version, build.Time)
+
+		return err
+	}
+}
+
+func checkCommand(ctx *cli.Context) error {
+	h, err := instance(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = h.Validate(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(ctx.Output, "all dependencies are resolved and config is valid")
+
+	return err
+}
+
+func graphCommand(ctx *cli.Context) error {
+	format, err := ctx.Flags.GetString(flagFormat)
+	if err != nil {
+		return err
+	}
+
+	invoke, err := ctx.Flags.GetBool(flagInvoke)
+	if err != nil {
+		return err
+	}
+
+	return printGraph(ctx, format, invoke)
+}
+
+// printGraph prints the dependency graph, when invoke is set, the application is constructed (without running)
+// to mark used constructors.
+func printGraph(ctx *cli.Context, format string, invoke bool) error {
+	h, err := instance(ctx)
+	if err != nil {
+		return err
+	}
+
+	if invoke {
+		if err = h.Invoke(func(App) {}); err != nil {
+			return err
+		}
+	}
+
+	return h.Graph().Write(ctx.Output, format)
+}
+
+func printCommand(ctx *cli.Context) error {
+	format, err := ctx.Flags.GetString(flagFormat)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Invoke(func(p printParams) error {
+		values := p.Viper.AllSettings()
+		if p.Secrets != nil {
+			values = p.Secrets.Redact(values)
+		}
+
+		return printSettings(ctx.Output, format, values)
+	})
+}
+
+func validateCommand(ctx *cli.Context) error {
+	h, err := instance(ctx)
+	if err != nil {
+		return err
+	}
+
+	if problems := h.validateConfig(); len(problems) > 0 {
+		return ValidationError(problems)
+	}
+
+	_, err = fmt.Fprintln(ctx.Output, "config is valid")
+
+	return err
+}
+
+// instance returns helium instance of the command.
+func instance(ctx *cli.Context) (*Helium, error) {
+	container, err := ctx.Container()
+	if err != nil {
+		return nil, err
+	}
+
+	h, ok := container.(*Helium)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnknownContainer, container)
+	}
+
+	return h, nil
+}
+
+func printSettings(out io.Writer, format string, values map[string]interface{}) error {
+	switch format {
+	case formatYAML:
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(values); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	case formatJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(values)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
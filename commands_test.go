@@ -0,0 +1,171 @@
+package helium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/helium/cli"
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/settings"
+)
+
+func TestExecute(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(config, []byte("ops:\n  address: :8081\n"), 0o600))
+
+	prepare := func(out *bytes.Buffer, commands ...*cli.Command) *Settings {
+		return &Settings{
+			Name:         "test-app",
+			BuildVersion: "v1.0.0",
+			BuildTime:    "now",
+			Output:       out,
+			Commands:     commands,
+		}
+	}
+
+	app := module.Module{
+		{Constructor: func() context.Context { return context.Background() }},
+		{Constructor: func() App { return heliumApp{} }},
+	}
+
+	t.Run("should run application by default", func(t *testing.T) {
+		require.NoError(t, Execute(prepare(new(bytes.Buffer)), nil, app, settings.Module))
+		require.ErrorIs(t, Execute(prepare(new(bytes.Buffer)), []string{"run"},
+			module.New(func() context.Context { return context.Background() }),
+			module.New(func() App { return heliumErrApp{} })), errTest)
+	})
+
+	t.Run("should print version", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, Execute(prepare(out), []string{"version"}))
+		require.Equal(t, "test-app v1.0.0 (build time: now)\n", out.String())
+	})
+
+	t.Run("should print config", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, Execute(prepare(out), []string{"config", "print", "-c", config}, settings.Module))
+		require.Equal(t, "ops:\n  address: :8081\n", out.String())
+
+		out.Reset()
+		require.NoError(t, Execute(prepare(out), []string{"--config", config, "config", "print", "--format", "json"},
+			settings.Module))
+		require.JSONEq(t, `{"ops":{"address":":8081"}}`, out.String())
+
+		require.ErrorIs(t, Execute(prepare(out), []string{"config", "print", "-f", "xml"}, settings.Module),
+			ErrUnknownFormat)
+	})
+
+	t.Run("should redact secrets in printed config", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "secret")
+
+		file := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(file, []byte("db:\n  user: app\n  password: env://TEST_DB_PASSWORD\n"), 0o600))
+
+		out := new(bytes.Buffer)
+		require.NoError(t, Execute(prepare(out), []string{"config", "print", "-c", file}, settings.Module))
+		require.Equal(t, "db:\n  password: '[REDACTED]'\n  user: app\n", out.String())
+		require.NotContains(t, out.String(), "secret")
+	})
+
+	t.Run("should validate config", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, Execute(prepare(out), []string{"config", "validate", "-c", config}, settings.Module))
+		require.Equal(t, "config is valid\n", out.String())
+
+		require.Error(t, Execute(prepare(out), []string{"config", "validate", "-c", "unknown.yml"}, settings.Module))
+	})
+
+	t.Run("should check dependencies", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, Execute(prepare(out), []string{"check"}, app))
+		require.Equal(t, "all dependencies are resolved and config is valid\n", out.String())
+
+		require.Error(t, Execute(prepare(out), []string{"check"}))
+	})
+
+	t.Run("should print dependency graph", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, Execute(prepare(out), []string{"graph"}, app))
+		require.Contains(t, out.String(), "digraph {")
+		require.Contains(t, out.String(), `label="github.com/im-kulikov/helium"`)
+
+		out.Reset()
+		require.NoError(t, Execute(prepare(out), []string{"graph", "--format", "json", "--invoke"}, app))
+
+		var graph module.Graph
+		require.NoError(t, json.Unmarshal(out.Bytes(), &graph))
+
+		constructed := make(map[string]bool)
+		for _, node := range graph.Nodes {
+			constructed[strings.Join(node.Outputs, ",")] = node.Constructed
+		}
+
+		require.True(t, constructed["helium.App"])
+		require.False(t, constructed["context.Context"], "context is not required by App")
+		require.False(t, constructed["*module.Tracker"])
+
+		require.ErrorIs(t, Execute(prepare(out), []string{"graph", "-f", "svg"}, app), module.ErrUnknownGraphFormat)
+	})
+
+	t.Run("should print dependency graph instead of running", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, Execute(prepare(out), []string{"run", "--graph"}, app))
+		require.Contains(t, out.String(), "digraph {")
+
+		// run is the default command
+		out.Reset()
+		require.NoError(t, Execute(prepare(out), []string{"--graph=json"}, app))
+
+		var graph module.Graph
+		require.NoError(t, json.Unmarshal(out.Bytes(), &graph))
+
+		for _, node := range graph.Nodes {
+			if strings.Join(node.Outputs, ",") == "helium.App" {
+				require.True(t, node.Constructed, "application is constructed")
+			}
+		}
+
+		require.ErrorIs(t, Execute(prepare(out), []string{"run", "--graph=svg"}, app), module.ErrUnknownGraphFormat)
+	})
+
+	t.Run("custom command should get dependencies and flags from settings", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		cmd := &cli.Command{
+			Name:  "migrate",
+			Flags: func(flags *pflag.FlagSet) { flags.Int("migrate.steps", 1, "count of steps") },
+			Run: func(ctx *cli.Context) error {
+				return ctx.Invoke(func(v *viper.Viper) {
+					require.Equal(t, 3, v.GetInt("migrate.steps"))
+					require.Equal(t, ":8081", v.GetString("ops.address"))
+				})
+			},
+		}
+
+		require.NoError(t, Execute(prepare(out, cmd),
+			[]string{"migrate", "--migrate.steps", "3", "-c", config}, settings.Module))
+	})
+	t.Run("flags should be used by conditions of modules", func(t *testing.T) {
+		type feature struct{}
+
+		out := new(bytes.Buffer)
+		cmd := &cli.Command{
+			Name:  "feature",
+			Flags: func(flags *pflag.FlagSet) { flags.Bool("feature.enabled", false, "enable feature") },
+			Run:   func(ctx *cli.Context) error { return ctx.Invoke(func(*feature) {}) },
+		}
+
+		mod := module.Combine(settings.Module, module.When("feature.enabled", module.New(func() *feature { return &feature{} })))
+
+		require.Error(t, Execute(prepare(out, cmd), []string{"feature", "-c", config}, mod))
+		require.NoError(t, Execute(prepare(out, cmd), []string{"feature", "--feature.enabled", "-c", config}, mod))
+	})
+}
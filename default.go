@@ -7,6 +7,6 @@ import (
 
 // DefaultApp defines default helium application and provides service.Module.
 // nolint:gochecknoglobals
-var DefaultApp = module.New(newDefaultApp).Append(service.Module)
+var DefaultApp = module.Named(module.Info{Name: "helium.DefaultApp"}, module.New(newDefaultApp), service.Module)
 
 func newDefaultApp(svc service.Group) App { return svc }
@@ -71,7 +71,10 @@ func TestDefaultApp(t *testing.T) {
 		require.NotNil(t, h)
 		require.NoError(t, err)
 
-		require.EqualError(t, h.Run(), errTest.Error())
+		// errors of services are reported with the name of the service and the phase
+		err = h.Run()
+		require.ErrorIs(t, err, errTest)
+		require.EqualError(t, err, `service "errService" start: `+errTest.Error())
 
 		cancel()
 	})
@@ -91,8 +94,10 @@ func TestDefaultApp(t *testing.T) {
 		require.NotNil(t, h)
 		require.NoError(t, err)
 
-		cancel()
+		// service is stopped only when it was started, it returns right after start and the group is stopped
 		require.NoError(t, h.Run())
 		require.EqualError(t, svc.Load(), errTest.Error())
+
+		cancel()
 	})
 }
@@ -0,0 +1,201 @@
+package helium
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/module"
+)
+
+type (
+	// Frame is the function from the chain of the error.
+	Frame struct {
+		Function string
+		File     string
+		Line     int
+	}
+
+	// Report is the diagnostic report of the error, see Diagnose.
+	Report struct {
+		Err error
+
+		// Cause is the root cause of the error.
+		Cause error
+
+		// Missing contains types, that could not be found in DI container.
+		Missing []string
+
+		// Chain contains constructors from the outer one to the failed constructor.
+		Chain []Frame
+
+		// Suggestions contains modules, that provide missing types.
+		Suggestions []string
+	}
+)
+
+const (
+	missingType  = "missing type: "
+	missingTypes = "missing types: "
+)
+
+// String returns function and its location.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+}
+
+// Diagnose creates report of the error: missing types, the chain of constructors that needed them
+// with their locations, and named modules that provide missing types (see module.Named).
+// The chain contains constructors tracked by the last created Helium (see module.Tracker).
+func Diagnose(err error) *Report {
+	report := &Report{Err: err, Cause: dig.RootCause(err)}
+
+	if tracker := appTracker.Load(); tracker != nil {
+		report.Chain = chainOf(err, report.Cause, tracker.Graph())
+	}
+
+	report.Missing = missing(report.Cause.Error())
+
+	seen := make(map[string]struct{})
+
+	for _, typ := range report.Missing {
+		for _, name := range module.Suggest(typ) {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+
+			seen[name] = struct{}{}
+			report.Suggestions = append(report.Suggestions, "add "+name)
+		}
+	}
+
+	return report
+}
+
+// String returns the report as plain text.
+func (r *Report) String() string {
+	var out strings.Builder
+
+	out.WriteString(r.Cause.Error())
+
+	if len(r.Chain) > 0 {
+		out.WriteString("\nchain:")
+
+		for i, frame := range r.Chain {
+			fmt.Fprintf(&out, "\n  %d. %s", i+1, frame)
+		}
+	}
+
+	if len(r.Suggestions) > 0 {
+		out.WriteString("\nsuggestions:")
+
+		for _, suggestion := range r.Suggestions {
+			fmt.Fprintf(&out, "\n  - %s", suggestion)
+		}
+	}
+
+	return out.String()
+}
+
+// Fields returns the report as fields of log entry.
+func (r *Report) Fields() []zap.Field {
+	fields := []zap.Field{zap.Error(r.Err)}
+
+	if r.Cause.Error() != r.Err.Error() {
+		fields = append(fields, zap.String("cause", r.Cause.Error()))
+	}
+
+	if len(r.Missing) > 0 {
+		fields = append(fields, zap.Strings("missing", r.Missing))
+	}
+
+	if len(r.Chain) > 0 {
+		chain := make([]string, 0, len(r.Chain))
+		for _, frame := range r.Chain {
+			chain = append(chain, frame.String())
+		}
+
+		fields = append(fields, zap.Strings("chain", chain))
+	}
+
+	if len(r.Suggestions) > 0 {
+		fields = append(fields, zap.Strings("suggestions", r.Suggestions))
+	}
+
+	return fields
+}
+
+// chainOf returns tracked constructors, that are mentioned by the error of DI container with their locations,
+// in order of the message: from the outer constructor to the failed one.
+func chainOf(err, cause error, graph *module.Graph) []Frame {
+	type located struct {
+		at    int
+		frame Frame
+	}
+
+	var (
+		found   []located
+		seen    = make(map[string]struct{})
+		message = strings.TrimSuffix(err.Error(), cause.Error())
+	)
+
+	for _, node := range graph.Nodes {
+		if _, ok := seen[node.Location]; ok || node.Location == "" {
+			continue
+		}
+
+		seen[node.Location] = struct{}{}
+
+		// DI container describes the constructor as `"package".name (file:line)`
+		if at := strings.Index(message, "("+node.Location+")"); at >= 0 {
+			found = append(found, located{at: at, frame: frameOf(node)})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].at < found[j].at })
+
+	chain := make([]Frame, 0, len(found))
+	for _, item := range found {
+		chain = append(chain, item.frame)
+	}
+
+	return chain
+}
+
+// frameOf returns the frame of the tracked constructor.
+func frameOf(node module.Node) Frame {
+	frame := Frame{Function: node.Package + "." + node.Name, File: node.Location}
+
+	if idx := strings.LastIndex(node.Location, ":"); idx > 0 {
+		frame.File = node.Location[:idx]
+		frame.Line, _ = strconv.Atoi(node.Location[idx+1:]) // nolint:errcheck // location is file:line
+	}
+
+	return frame
+}
+
+// missing parses the message of DI container and returns missing types.
+func missing(message string) []string {
+	switch {
+	case strings.HasPrefix(message, missingType):
+		message = strings.TrimPrefix(message, missingType)
+	case strings.HasPrefix(message, missingTypes):
+		message = strings.TrimPrefix(message, missingTypes)
+	default:
+		return nil
+	}
+
+	list := strings.Split(message, "; ")
+	for i := range list {
+		// suggestions of DI container, e.g. `*T (did you mean T?)`
+		if idx := strings.Index(list[i], " (did you mean"); idx > 0 {
+			list[i] = list[i][:idx]
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,94 @@
+package helium
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/im-kulikov/helium/logger"
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/settings"
+	"github.com/im-kulikov/helium/web"
+)
+
+type diagnoseServer struct{}
+
+func newDiagnoseServer(*web.OpsConfig, *zap.Logger) *diagnoseServer { return &diagnoseServer{} }
+
+func newDiagnoseApp(*diagnoseServer) App { return heliumApp{} }
+
+func TestDiagnose(t *testing.T) {
+	run := func(h *Helium) error { return h.Invoke(func(App) {}) }
+
+	t.Run("should report missing types, chain and modules", func(t *testing.T) {
+		h, err := New(&Settings{}, settings.Module, module.New(newDiagnoseServer), module.New(newDiagnoseApp))
+		require.NoError(t, err)
+
+		report := Diagnose(run(h))
+		require.Equal(t, []string{"*web.OpsConfig", "*zap.Logger"}, report.Missing)
+		require.Equal(t, []string{"add web.OpsModule", "add logger.Module"}, report.Suggestions)
+
+		require.Len(t, report.Chain, 2)
+		require.Equal(t, "github.com/im-kulikov/helium.newDiagnoseApp", report.Chain[0].Function)
+		require.Equal(t, "github.com/im-kulikov/helium.newDiagnoseServer", report.Chain[1].Function)
+		require.Contains(t, report.Chain[1].File, "diagnose_test.go")
+		require.NotZero(t, report.Chain[1].Line)
+
+		text := report.String()
+		require.Contains(t, text, "missing types: *web.OpsConfig; *zap.Logger\nchain:\n  1. github.com/im-kulikov/helium.newDiagnoseApp (")
+		require.Contains(t, text, "  2. github.com/im-kulikov/helium.newDiagnoseServer (")
+		require.Contains(t, text, "\nsuggestions:\n  - add web.OpsModule\n  - add logger.Module")
+	})
+
+	t.Run("should report failed constructor", func(t *testing.T) {
+		h, err := New(nil, module.New(func() (App, error) { return nil, errTest }))
+		require.NoError(t, err)
+
+		report := Diagnose(run(h))
+		require.ErrorIs(t, report.Cause, errTest)
+		require.Empty(t, report.Missing)
+		require.Empty(t, report.Suggestions)
+		require.Len(t, report.Chain, 1)
+		require.Equal(t, "test\nchain:\n  1. "+report.Chain[0].String(), report.String())
+	})
+
+	t.Run("should report simple errors", func(t *testing.T) {
+		report := Diagnose(errTest)
+		require.Equal(t, "test", report.String())
+		require.Len(t, report.Fields(), 1)
+	})
+
+	t.Run("should log report", func(t *testing.T) {
+		var exitCode int
+
+		monkey.Patch(os.Exit, func(code int) { exitCode = code })
+		monkey.Patch(log.Fatal, func(...interface{}) { exitCode = 2 })
+
+		defer monkey.UnpatchAll()
+
+		core, logs := observer.New(zap.ErrorLevel)
+		monkey.Patch(logger.NewLogger, func(*logger.Config, *settings.Core) (*zap.Logger, error) {
+			return zap.New(core), nil
+		})
+
+		h, err := New(nil, module.New(newDiagnoseApp))
+		require.NoError(t, err)
+
+		Catch(run(h))
+		require.Equal(t, ExitDependency, exitCode)
+
+		entries := logs.FilterMessage("Can't run app").All()
+		require.Len(t, entries, 1)
+
+		fields := entries[0].ContextMap()
+		require.Equal(t, "missing type: *helium.diagnoseServer", fields["cause"])
+		require.Equal(t, []interface{}{"*helium.diagnoseServer"}, fields["missing"])
+		require.Len(t, fields["chain"], 1)
+		require.NotContains(t, fields, "suggestions")
+	})
+}
@@ -0,0 +1,155 @@
+package helium
+
+import (
+	"errors"
+	stdlog "log"
+	"net"
+	"os"
+
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/group"
+	"github.com/im-kulikov/helium/logger"
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/settings"
+)
+
+type (
+	// ExitCoder is implemented by errors, that define exit code of the process (see ExitCode).
+	ExitCoder interface {
+		ExitCode() int
+	}
+
+	// ExitError allows to set exit code for any error, see WithExitCode.
+	ExitError struct {
+		Code int
+		Err  error
+	}
+)
+
+// Exit codes of the process, they follow sysexits.h where it is possible.
+const (
+	// ExitOK is used when application was stopped normally.
+	ExitOK = 0
+
+	// ExitFailure is used for all unknown errors.
+	ExitFailure = 1
+
+	// ExitDependency is used when dependencies could not be resolved (EX_SOFTWARE).
+	ExitDependency = 70
+
+	// ExitListen is used when listener could not be bound (EX_OSERR).
+	ExitListen = 71
+
+	// ExitShutdownTimeout is used when services were not stopped in time (EX_TEMPFAIL).
+	ExitShutdownTimeout = 75
+
+	// ExitConfig is used when config could not be read or is invalid (EX_CONFIG).
+	ExitConfig = 78
+)
+
+var (
+	_ error     = (*ExitError)(nil)
+	_ ExitCoder = (*ExitError)(nil)
+)
+
+// WithExitCode wraps the error, so the process exits with passed code (see Exit).
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ExitError{Code: code, Err: err}
+}
+
+// Error returns message of the original error.
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the original error.
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// ExitCode returns exit code of the error.
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// ExitCode returns exit code of the process for the error:
+// - errors, that implement ExitCoder, define the code by themselves;
+// - the first error of services (group.Errors) or the first problem of validation (ValidationError) defines the code;
+// - shutdown timeout, listener bind, config and DI errors have their own codes (see ExitConfig and others);
+// - ExitFailure is used for other errors.
+func ExitCode(err error) int {
+	var (
+		coder    ExitCoder
+		services group.Errors
+		problems ValidationError
+		opErr    *net.OpError
+		digErr   dig.Error
+	)
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &coder):
+		return coder.ExitCode()
+	case errors.As(err, &services) && len(services) > 0:
+		return ExitCode(services[0])
+	case errors.As(err, &problems) && len(problems) > 0:
+		if problems[0].Source == sourceConfig {
+			return ExitConfig
+		}
+
+		return ExitCode(problems[0].Err)
+	case errors.Is(err, group.ErrShutdownTimeout):
+		return ExitShutdownTimeout
+	case errors.As(err, &opErr) && opErr.Op == "listen":
+		return ExitListen
+	case errors.Is(err, settings.ErrConfig):
+		return ExitConfig
+	case errors.Is(err, module.ErrProviderConflict),
+		errors.Is(err, module.ErrVersionConflict),
+		errors.Is(err, module.ErrRequiredModule),
+		errors.Is(err, module.ErrOverride),
+		errors.As(dig.RootCause(err), &digErr):
+		return ExitDependency
+	default:
+		return ExitFailure
+	}
+}
+
+// Exit logs the diagnostic report of the error (see Diagnose), flushes the logger
+// and exits with the code of the error (see ExitCode). It exits with ExitOK, when error is nil.
+// The logger of the application (see Helium.Run) is used, when the application was not run,
+// the logger is created by settings.
+func Exit(err error) {
+	code := ExitCode(err)
+	if err == nil {
+		os.Exit(code)
+
+		return
+	}
+
+	var (
+		log    = appLogger.Load()
+		logErr error
+	)
+
+	if log == nil {
+		log, logErr = logger.NewLogger(logger.NewLoggerConfig(settings.Viper()), &settings.Core{
+			Name:         appName.Load(),
+			BuildVersion: appVersion.Load(),
+		})
+	}
+
+	if logErr != nil {
+		stdlog.Println(Diagnose(err))
+	} else {
+		logServicesErrors(log, err)
+
+		log.Error("Can't run app", append(Diagnose(err).Fields(), zap.Int("exit_code", code))...)
+
+		// stdout could not be synced on some platforms, nothing to do with it before exit
+		_ = log.Sync()
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,141 @@
+package helium
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/im-kulikov/helium/group"
+	"github.com/im-kulikov/helium/logger"
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/settings"
+)
+
+type exitMissing struct{}
+
+func TestExitCode(t *testing.T) {
+	invoke := func(fn interface{}) error {
+		di := dig.New()
+		require.NoError(t, di.Provide(func() *settings.Core { return &settings.Core{File: "unknown file"} }))
+		require.NoError(t, di.Provide(settings.New))
+		require.NoError(t, di.Provide(func() (net.Listener, error) { return net.Listen("tcp", "127.0.0.1:-1") }))
+
+		return di.Invoke(fn)
+	}
+
+	// servers bind their listeners on start, so the error is returned by the group of services
+	_, listenErr := net.Listen("tcp", "127.0.0.1:-1")
+
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "nil", err: nil, code: ExitOK},
+		{name: "unknown error", err: errTest, code: ExitFailure},
+		{name: "custom code", err: fmt.Errorf("wrapped: %w", WithExitCode(errTest, 3)), code: 3},
+		{name: "shutdown timeout", err: group.Errors{
+			{Service: "srv", Phase: group.PhaseTimeout, Err: group.ErrShutdownTimeout},
+		}, code: ExitShutdownTimeout},
+		{name: "first error of services", err: group.Errors{
+			{Service: "first", Phase: group.PhaseStart, Err: errTest},
+			{Service: "second", Phase: group.PhaseTimeout, Err: group.ErrShutdownTimeout},
+		}, code: ExitFailure},
+		{name: "listener bind", err: invoke(func(net.Listener) {}), code: ExitListen},
+		{name: "listener bind on start", err: group.Errors{
+			{Service: "http(api)", Phase: group.PhaseStart, Err: listenErr},
+		}, code: ExitListen},
+		{name: "config file", err: invoke(func(*viper.Viper) {}), code: ExitConfig},
+		{name: "missing dependency", err: invoke(func(*exitMissing) {}), code: ExitDependency},
+		{name: "provider conflict", err: module.Module{
+			{Constructor: func() int { return 1 }},
+			{Constructor: func() int { return 2 }},
+		}.Check(), code: ExitDependency},
+		{name: "config validation", err: ValidationError{{Source: sourceConfig, Err: errTest}}, code: ExitConfig},
+		{name: "dependency validation", err: ValidationError{{Source: sourceApp, Err: invoke(func(*exitMissing) {})}}, code: ExitDependency},
+	}
+
+	for i := range cases {
+		tt := cases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.code, ExitCode(tt.err))
+		})
+	}
+
+	t.Run("should not wrap nil", func(t *testing.T) {
+		require.NoError(t, WithExitCode(nil, 3))
+	})
+}
+
+func TestExit(t *testing.T) {
+	t.Run("should log report and exit with mapped code", func(t *testing.T) {
+		var exitCode int
+
+		monkey.Patch(os.Exit, func(code int) { exitCode = code })
+		defer monkey.UnpatchAll()
+
+		core, logs := observer.New(zap.ErrorLevel)
+		monkey.Patch(logger.NewLogger, func(*logger.Config, *settings.Core) (*zap.Logger, error) {
+			return zap.New(core), nil
+		})
+
+		appLogger.Store(nil) // the application was not run
+		Exit(WithExitCode(errTest, 42))
+		require.Equal(t, 42, exitCode)
+
+		entries := logs.FilterMessage("Can't run app").All()
+		require.Len(t, entries, 1)
+		require.Equal(t, errTest.Error(), entries[0].ContextMap()["error"])
+		require.Equal(t, int64(42), entries[0].ContextMap()["exit_code"])
+	})
+
+	t.Run("should log by the logger of the application", func(t *testing.T) {
+		var (
+			exitCode int
+			created  bool
+		)
+
+		monkey.Patch(os.Exit, func(code int) { exitCode = code })
+		defer monkey.UnpatchAll()
+
+		monkey.Patch(logger.NewLogger, func(*logger.Config, *settings.Core) (*zap.Logger, error) {
+			created = true
+
+			return zap.NewNop(), nil
+		})
+
+		core, logs := observer.New(zap.ErrorLevel)
+		h, err := New(nil, module.Module{
+			{Constructor: func() context.Context { return context.Background() }},
+			{Constructor: func() *zap.Logger { return zap.New(core) }},
+			{Constructor: func() App {
+				return &readyApp{ready: make(chan struct{}), runs: func(context.Context) error { return errTest }}
+			}},
+		})
+		require.NoError(t, err)
+
+		Exit(h.Run())
+		require.Equal(t, ExitFailure, exitCode)
+		require.False(t, created, "logger should not be created")
+		require.Len(t, logs.FilterMessage("Can't run app").All(), 1)
+	})
+
+	t.Run("should exit with zero code on nil", func(t *testing.T) {
+		exitCode := -1
+
+		monkey.Patch(os.Exit, func(code int) { exitCode = code })
+		defer monkey.UnpatchAll()
+
+		Exit(nil)
+		require.Equal(t, ExitOK, exitCode)
+	})
+}
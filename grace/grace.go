@@ -2,24 +2,53 @@ package grace
 
 import (
 	"context"
+	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
 	"go.uber.org/zap"
 
 	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/settings"
 )
 
+type params struct {
+	dig.In
+
+	Logger *zap.Logger
+	Viper  *viper.Viper `optional:"true"`
+}
+
 // Module graceful context.
 // nolint:gochecknoglobals
-var Module = module.Module{
-	{Constructor: NewGracefulContext},
-}
+var Module = module.Named(module.Info{Name: "grace.Module"}, module.Module{
+	{Constructor: newContext},
+})
 
-// NewGracefulContext returns graceful context.
+// NewGracefulContext returns graceful context, that is canceled on SIGINT, SIGTERM or SIGHUP.
 func NewGracefulContext(l *zap.Logger) context.Context {
-	ctx, _ := signal.NotifyContext(context.Background(),
-		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	return notifyContext(l, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+}
+
+// NewReloadableContext returns graceful context, that is canceled on SIGINT or SIGTERM.
+// SIGHUP is not handled, it is used to reload config (see settings.WatchKey).
+func NewReloadableContext(l *zap.Logger) context.Context {
+	return notifyContext(l, syscall.SIGINT, syscall.SIGTERM)
+}
+
+// newContext returns reloadable context, when watch mode of config is enabled.
+func newContext(p params) context.Context {
+	if p.Viper != nil && p.Viper.GetBool(settings.WatchKey) {
+		return NewReloadableContext(p.Logger)
+	}
+
+	return NewGracefulContext(p.Logger)
+}
+
+func notifyContext(l *zap.Logger, signals ...os.Signal) context.Context {
+	ctx, _ := signal.NotifyContext(context.Background(), signals...)
 
 	go func() {
 		<-ctx.Done()
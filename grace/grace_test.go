@@ -2,12 +2,18 @@ package grace
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/settings"
 )
 
 func TestGrace(t *testing.T) {
@@ -35,3 +41,37 @@ func TestGrace(t *testing.T) {
 		})
 	}
 }
+
+func TestReloadableContext(t *testing.T) {
+	v := viper.New()
+	v.Set(settings.WatchKey, true)
+
+	// SIGHUP is handled by config reloader, when watch mode is enabled
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	defer signal.Stop(hup)
+
+	ctx := newContext(params{Logger: zap.NewNop(), Viper: v})
+
+	// waiting to run the goroutine and channel of signals
+	<-time.After(time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case <-hup:
+	case <-ctx.Done():
+		t.Fatal("context should not be canceled on SIGHUP")
+	}
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("no signal")
+	}
+
+	require.NoError(t, newContext(params{Logger: zap.NewNop()}).Err())
+}
@@ -0,0 +1,93 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+type (
+	// Phase of the service lifecycle, where error has occurred.
+	Phase string
+
+	// Error of the service, it contains the name of the service and the phase where error has occurred.
+	Error struct {
+		Service string
+		Phase   Phase
+		Err     error
+	}
+
+	// Errors collects errors of all services of the group.
+	// It allows to check any of them by errors.Is and errors.As.
+	Errors []*Error
+)
+
+const (
+	// PhaseStart is used for errors returned by service callback.
+	PhaseStart Phase = "start"
+
+	// PhaseStop is used for errors returned by service shutdown function.
+	PhaseStop Phase = "stop"
+
+	// PhaseTimeout is used when service was not stopped in time.
+	PhaseTimeout Phase = "timeout"
+)
+
+// ErrShutdownTimeout is used when service was not stopped in time.
+const ErrShutdownTimeout = internal.Error("shutdown timeout")
+
+var (
+	_ error = (*Error)(nil)
+	_ error = (Errors)(nil)
+)
+
+// Error returns error message as string.
+func (e *Error) Error() string {
+	return fmt.Sprintf("service %q %s: %v", e.Service, e.Phase, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Error returns error messages of all services as string.
+func (e Errors) Error() string {
+	list := make([]string, 0, len(e))
+	for i := range e {
+		list = append(list, e[i].Error())
+	}
+
+	return strings.Join(list, "; ")
+}
+
+// Is reports whether any error of services matches target.
+func (e Errors) Is(target error) bool {
+	for i := range e {
+		if errors.Is(e[i], target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error of services that matches target.
+func (e Errors) As(target interface{}) bool {
+	for i := range e {
+		if errors.As(e[i], target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Err returns nil when there are no errors.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -3,8 +3,11 @@ package group
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
+
+	"github.com/im-kulikov/helium/internal"
 )
 
 type (
@@ -12,25 +15,97 @@ type (
 		ignore   []error
 		services []service
 		shutdown time.Duration
+		restart  RestartConfig
+		failure  FailureHandler
+		recovery bool
+		panics   PanicHandler
+		report   ShutdownReporter
+
+		once  sync.Once
+		ready chan struct{}
+
+		// runtime state of the group, guarded by mu.
+		mu        sync.Mutex
+		ctx       context.Context
+		units     []*unit
+		running   bool
+		stopping  bool
+		detaching sync.WaitGroup
+
+		leave sync.Once
+		exit  chan struct{}
 	}
 
 	service struct {
+		name      string
+		after     []string
+		phase     int
+		waitReady bool
+		optional  bool
+		restart   *RestartConfig
+
+		shutdownTimeout time.Duration
+
 		callback Callback
 		shutdown Shutdown
+
+		status *status
 	}
 
+	// unit is a runtime representation of the service.
+	unit struct {
+		*service
+
+		mu      sync.Mutex
+		ctx     context.Context
+		cancel  context.CancelFunc
+		started bool
+
+		owner  *group
+		deps   []*unit
+		super  *supervisor
+		failed func(error)
+		once   sync.Once
+		ready  chan struct{}
+		done   chan struct{}
+
+		// results of the service, should be read only when service is stopped.
+		// startErr is guarded by mu, because abandoned service may return at any time.
+		startErr error
+		stopErr  error
+		timeout  bool
+	}
+
+	readyKey struct{}
+
 	// Shutdown function that receives shutdown context and allows to gracefully stop an service.
-	Shutdown func(context.Context)
+	// It can return an error, when service could not be stopped.
+	Shutdown func(context.Context) error
 
 	// Callback function that will be called on service starts.
 	Callback func(context.Context) error
 
+	// FailureHandler is called when optional service fails, it receives the name of the service and the error.
+	FailureHandler func(string, error)
+
 	// Service collects services and runs them concurrently.
-	// - when any service returns, all services will be stopped.
-	// - when context canceled or deadlined all services will be stopped.
+	// - services start only when their dependencies (see After and StartPhase) are up.
+	// - when any service returns (and should not be restarted), all services will be stopped in reverse order.
+	// - when optional service returns, it is reported to FailureHandler and other services keep running.
+	// - when context canceled or deadlined all services will be stopped in reverse order.
+	// - Ready channel will be closed when all services are up.
+	// - every service has its own shutdown budget (see ShutdownTimeout), services that were not stopped in time are abandoned.
+	// - Run returns Errors that contain start, stop and timeout errors of all services.
+	// - panics of services are recovered and returned as PanicError (see WithPanicRecovery).
+	// - States returns current states of all services.
+	// - Attach and Detach allow to add and gracefully stop services of the running group.
 	Service interface {
-		Add(Callback, Shutdown) Service
+		Add(Callback, Shutdown, ...ServiceOption) Service
+		Attach(Callback, Shutdown, ...ServiceOption) error
+		Detach(context.Context, string) error
 		Run(context.Context) error
+		Ready() <-chan struct{}
+		States() []ServiceState
 	}
 )
 
@@ -51,6 +126,9 @@ func New(options ...Option) Service {
 	runner := &group{
 		shutdown: defaultShutdown,
 		ignore:   defaultIgnoredErrors,
+		recovery: true,
+		ready:    make(chan struct{}),
+		exit:     make(chan struct{}),
 	}
 
 	for _, o := range options {
@@ -60,17 +138,64 @@ func New(options ...Option) Service {
 	return runner
 }
 
-// Add an service (callback and shutdown) to the group.
+// NotifyReady marks the service, that owns passed context, as up.
+// Services that depend on it will be started after that.
+// It should be called from the Callback of service that was added with WaitReady option,
+// for other services it does nothing.
+func NotifyReady(ctx context.Context) {
+	if ready, ok := ctx.Value(readyKey{}).(func()); ok {
+		ready()
+	}
+}
+
+// Add an service (callback and shutdown) to the group, it should be called before Run (see Attach).
+// Names of services should be unique, otherwise Run returns ErrServiceExists.
 // Canceling context shutdowns all running services.
 // The first service (callback function) to return shutdowns all running services.
 // The context.Context passed into shutdown function needed to gracefully shutdown services.
-func (g *group) Add(callback Callback, stopper Shutdown) Service {
-	g.services = append(g.services, service{
+func (g *group) Add(callback Callback, stopper Shutdown, options ...ServiceOption) Service {
+	svc := newService(callback, stopper, options...)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.services = append(g.services, svc)
+
+	return g
+}
+
+func newService(callback Callback, stopper Shutdown, options ...ServiceOption) service {
+	svc := service{
 		callback: callback,
 		shutdown: stopper,
-	})
+	}
 
-	return g
+	for _, o := range options {
+		o(&svc)
+	}
+
+	svc.status = newStatus(svc.name)
+
+	return svc
+}
+
+// Ready returns channel that will be closed when all services are up.
+// Channel will never be closed, when any service will be stopped before it is up.
+func (g *group) Ready() <-chan struct{} { return g.ready }
+
+func (g *group) markReady() { g.once.Do(func() { close(g.ready) }) }
+
+// waitReady waits until all services are up or halt channel is closed.
+func (g *group) waitReady(units []*unit, halt <-chan struct{}) {
+	for i := range units {
+		select {
+		case <-units[i].ready:
+		case <-halt:
+			return
+		}
+	}
+
+	g.markReady()
 }
 
 func (g *group) checkAndIgnore(err error) error {
@@ -85,60 +210,287 @@ func (g *group) checkAndIgnore(err error) error {
 
 // Run allows to run all services (callback function).
 // - method blocks until all services will be stopped.
+// - service starts only when all services it depends on are up.
 // - when context will be canceled or deadline exceeded we calls shutdown for services.
 // - when the first service (callback function) returns, all other services will be notified to stop.
+// - optional services do not stop other services, their errors are passed to FailureHandler.
+// - services in supervisor mode (see Restart and WithRestart) are restarted until restarts budget is exhausted.
+// - services are stopped in reverse order: dependent services are stopped before their dependencies.
+// - returns an error when services have unknown or circular dependencies, not unique names or unknown restart policy.
+// - returns Errors that contain start, stop and timeout errors of all services except ignored errors.
+// - panic of the service is recovered and handled as an error of the service, when recovery is enabled.
 func (g *group) Run(ctx context.Context) error {
+	units, err := g.start(ctx)
+	if err != nil || len(units) == 0 {
+		return err
+	}
+
+	halt := make(chan struct{})
+	go g.waitReady(units, halt)
+
+	// wait for context.Done() or any service returns:
+	select {
+	case <-g.exit:
+	case <-ctx.Done():
+	}
+
+	close(halt)
+
+	// services could not be attached or detached after that
+	g.mu.Lock()
+	g.stopping = true
+	units = g.units
+	g.mu.Unlock()
+
+	results := make([]ShutdownResult, len(units))
+
+	// notify services to stop in reverse order and wait until they will gracefully stopped or abandoned
+	order := stages(units)
+	for i := len(order) - 1; i >= 0; i-- {
+		wg := new(sync.WaitGroup)
+		wg.Add(len(order[i]))
+
+		for _, idx := range order[i] {
+			go func(idx int) {
+				defer wg.Done()
+
+				results[idx] = units[idx].stop(context.Background())
+			}(idx)
+		}
+
+		wg.Wait()
+	}
+
+	// wait until detached services will be stopped
+	g.detaching.Wait()
+
+	if g.report != nil {
+		g.report(results)
+	}
+
+	// return errors of all services except ignored errors
+	return g.collect(units).Err()
+}
+
+// start prepares units of all services and runs them, every service waits for its dependencies.
+func (g *group) start(ctx context.Context) ([]*unit, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	if len(g.services) == 0 {
-		return nil
+		g.stopping = true
+		g.markReady()
+
+		return nil, nil
+	}
+
+	if err := g.validate(); err != nil {
+		return nil, err
 	}
 
-	var (
-		cnt int
-		err error
-		res = make(chan error, len(g.services))
-	)
+	deps, err := dependencies(g.services)
+	if err != nil {
+		return nil, err
+	}
 
-	// we should add cancel to prevent service freeze
-	top, cancel := context.WithCancel(ctx)
+	if _, err = levels(g.services, deps); err != nil {
+		return nil, err
+	}
 
-	// run all services
+	units := make([]*unit, len(g.services))
 	for i := range g.services {
-		go func(callback Callback) { res <- callback(top) }(g.services[i].callback)
+		units[i] = g.newUnit(ctx, g.services[i])
 	}
 
-	// wait for context.Done() or error will be received:
-	select {
-	case err = <-res:
-		cnt = 1 // first error received, ignore it in future
-	case <-top.Done():
-		err = top.Err()
+	for i := range deps {
+		for _, dep := range deps[i] {
+			units[i].deps = append(units[i].deps, units[dep])
+		}
 	}
 
-	cancel()
+	g.ctx = ctx
+	g.running = true
+	g.units = append([]*unit(nil), units...)
 
-	// prepare graceful context to stop
-	grace, stop := context.WithTimeout(context.Background(), g.shutdown)
-	defer stop()
+	for i := range units {
+		go units[i].run()
+	}
+
+	return units, nil
+}
+
+// stop notifies the group to stop all services.
+func (g *group) stop() { g.leave.Do(func() { close(g.exit) }) }
+
+// collect returns errors of all services except ignored errors.
+func (g *group) collect(units []*unit) Errors {
+	var result Errors
+
+	for _, u := range units {
+		u.mu.Lock()
+		startErr := u.startErr
+		u.mu.Unlock()
+
+		if err := g.checkAndIgnore(startErr); err != nil {
+			result = append(result, &Error{Service: u.name, Phase: PhaseStart, Err: err})
+		}
+
+		if err := g.checkAndIgnore(u.stopErr); err != nil {
+			result = append(result, &Error{Service: u.name, Phase: PhaseStop, Err: err})
+		}
+
+		if u.timeout && g.checkAndIgnore(ErrShutdownTimeout) != nil {
+			result = append(result, &Error{Service: u.name, Phase: PhaseTimeout, Err: ErrShutdownTimeout})
+		}
+	}
+
+	return result
+}
+
+// reporter returns function that passes errors of optional service to FailureHandler.
+func (g *group) reporter(name string) func(error) {
+	return func(err error) {
+		if err = g.checkAndIgnore(err); err == nil || g.failure == nil {
+			return
+		}
+
+		g.failure(name, err)
+	}
+}
 
-	// we should wait until all services will gracefully stopped
-	wg := new(sync.WaitGroup)
-	defer wg.Wait()
+func (g *group) validate() error {
+	if err := g.restart.validate(); err != nil {
+		return err
+	}
 
-	wg.Add(len(g.services))
-	// notify all services to stop
+	names := make(map[string]struct{}, len(g.services))
 	for i := range g.services {
-		go func(shutdown Shutdown) {
-			defer wg.Done()
+		if name := g.services[i].name; name != "" {
+			if _, ok := names[name]; ok {
+				return fmt.Errorf("%w: %q", ErrServiceExists, name)
+			}
+
+			names[name] = struct{}{}
+		}
+
+		if g.services[i].restart == nil {
+			continue
+		}
+
+		if err := g.services[i].restart.validate(); err != nil {
+			return fmt.Errorf("service %q: %w", g.services[i].name, err)
+		}
+	}
+
+	return nil
+}
+
+// newUnit creates unit for the copy of the service.
+func (g *group) newUnit(ctx context.Context, svc service) *unit {
+	restart := g.restart
+	if svc.restart != nil {
+		restart = *svc.restart
+	}
 
-			shutdown(grace)
-		}(g.services[i].shutdown)
+	// service context should not be canceled by parent context,
+	// because services must be stopped in reverse order.
+	ctx, cancel := context.WithCancel(internal.Detached{Context: ctx})
+
+	return &unit{
+		service: &svc,
+		ctx:     ctx,
+		cancel:  cancel,
+		owner:   g,
+		failed:  g.reporter(svc.name),
+		super:   newSupervisor(restart),
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (u *unit) markReady() {
+	u.once.Do(func() {
+		u.status.set(StateRunning, nil)
+
+		close(u.ready)
+	})
+}
+
+// run waits for dependencies and calls service callback.
+// It does nothing when service was stopped before dependencies are up.
+// When service returns by itself (it was not stopped or detached), the group will be stopped.
+func (u *unit) run() {
+	defer close(u.done)
+
+	u.status.set(StateStarting, nil)
+
+	for _, dep := range u.deps {
+		select {
+		case <-dep.ready:
+		case <-u.ctx.Done():
+			return
+		}
+	}
+
+	u.mu.Lock()
+	if u.ctx.Err() != nil {
+		u.mu.Unlock()
+
+		return
+	}
+
+	u.started = true
+	u.mu.Unlock()
+
+	if !u.waitReady {
+		u.markReady()
+	}
+
+	// service has returned, so it is not running anymore, even when the group is still running
+	err := u.supervise(context.WithValue(u.ctx, readyKey{}, u.markReady))
+	if failure := u.owner.checkAndIgnore(err); failure != nil {
+		u.status.set(StateFailed, failure)
+	} else {
+		u.status.set(StateStopped, nil)
+	}
+
+	if !u.optional {
+		u.mu.Lock()
+		u.startErr = err
+		u.mu.Unlock()
+
+		if u.ctx.Err() == nil {
+			u.owner.stop()
+		}
+
+		return
 	}
 
-	// wait when all services will stop
-	for i := cnt; i < cap(res); i++ {
-		<-res
+	// optional service should not stop the group
+	if u.ctx.Err() == nil {
+		u.failed(err)
 	}
+}
+
+// supervise calls service callback and restarts it according to the restart policy.
+func (u *unit) supervise(ctx context.Context) error {
+	for {
+		err := u.owner.protect(u.name, func() error { return u.callback(ctx) })
+		if ctx.Err() != nil {
+			return err
+		}
+
+		delay, ok, fail := u.super.restart(err, time.Now())
+		if !ok {
+			return fail
+		}
+
+		u.status.restarted(err)
 
-	// return only first error except ignored errors
-	return g.checkAndIgnore(err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
 }
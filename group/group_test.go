@@ -2,10 +2,12 @@ package group
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 
 	"github.com/im-kulikov/helium/internal"
 )
@@ -23,6 +25,7 @@ type testCase struct {
 	shutdown time.Duration
 
 	expect error
+	also   []error
 }
 
 const (
@@ -31,9 +34,15 @@ const (
 	defaultAwait = time.Millisecond * 10
 )
 
+func noopShutdown(context.Context) error { return nil }
+
 func TestNew(t *testing.T) {
 	var cases []testCase
 
+	// releases services that ignore shutdown budget, when all cases are done
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
 	{ // empty
 		ctx, cancel := context.WithCancel(context.Background())
 		cases = append(cases, testCase{
@@ -60,12 +69,12 @@ func TestNew(t *testing.T) {
 			expect:   errAlways,
 			services: []service{
 				{
-					shutdown: func(ctx context.Context) { <-ctx.Done() },
+					shutdown: func(ctx context.Context) error { <-ctx.Done(); return nil },
 					callback: func(context.Context) error { return errAlways },
 				},
 
 				{
-					shutdown: func(ctx context.Context) { <-ctx.Done() },
+					shutdown: func(ctx context.Context) error { <-ctx.Done(); return nil },
 					callback: func(ctx context.Context) error {
 						// should not freeze
 						<-ctx.Done()
@@ -91,7 +100,7 @@ func TestNew(t *testing.T) {
 			expect:   errAlways,
 			services: []service{
 				{
-					shutdown: func(ctx context.Context) { <-ctx.Done() },
+					shutdown: func(ctx context.Context) error { <-ctx.Done(); return nil },
 					callback: func(context.Context) error { return errAlways },
 				},
 			},
@@ -109,16 +118,20 @@ func TestNew(t *testing.T) {
 			await: defaultAwait,
 
 			shutdown: defaultAwait / 4,
+
+			// shutdown functions wait for the whole budget
+			ignore: []error{ErrShutdownTimeout},
+
 			services: []service{
 				{
-					shutdown: func(ctx context.Context) { <-ctx.Done() },
+					shutdown: func(ctx context.Context) error { <-ctx.Done(); return nil },
 					callback: func(ctx context.Context) error {
 						<-ctx.Done()
 						return ctx.Err()
 					},
 				},
 				{
-					shutdown: func(ctx context.Context) { <-ctx.Done() },
+					shutdown: func(ctx context.Context) error { <-ctx.Done(); return nil },
 					callback: func(ctx context.Context) error {
 						<-ctx.Done()
 						return ctx.Err()
@@ -141,16 +154,19 @@ func TestNew(t *testing.T) {
 
 			shutdown: time.Nanosecond,
 
+			// budget is too small to wait for services
+			ignore: []error{ErrShutdownTimeout},
+
 			services: []service{
 				{
-					shutdown: func(ctx context.Context) { <-ctx.Done() },
+					shutdown: func(ctx context.Context) error { <-ctx.Done(); return nil },
 					callback: func(ctx context.Context) error {
 						cancel()
 						return ctx.Err()
 					},
 				},
 				{
-					shutdown: func(ctx context.Context) { <-ctx.Done() },
+					shutdown: func(ctx context.Context) error { <-ctx.Done(); return nil },
 					callback: func(ctx context.Context) error {
 						<-ctx.Done()
 						return ctx.Err()
@@ -160,6 +176,69 @@ func TestNew(t *testing.T) {
 		})
 	}
 
+	{ // should report service that was not stopped in time
+		ctx, cancel := context.WithCancel(context.Background())
+
+		cases = append(cases, testCase{
+			name: "should report service that was not stopped in time",
+
+			ctx:    ctx,
+			cancel: cancel,
+
+			await: defaultAwait,
+
+			shutdown: defaultAwait / 4,
+			expect:   ErrShutdownTimeout,
+			services: []service{
+				{
+					shutdown: noopShutdown,
+					callback: func(ctx context.Context) error {
+						cancel()
+						<-ctx.Done()
+						<-release // ignores shutdown budget
+						return nil
+					},
+				},
+			},
+		})
+	}
+
+	{ // should report errors with shutdown timeout
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+
+		cases = append(cases, testCase{
+			name: "should report errors with shutdown timeout",
+
+			ctx:    ctx,
+			cancel: cancel,
+
+			await: defaultAwait,
+
+			shutdown: defaultAwait / 4,
+			expect:   ErrShutdownTimeout,
+			also:     []error{errAlways},
+			services: []service{
+				{
+					shutdown: noopShutdown,
+					callback: func(context.Context) error {
+						<-started
+						return errAlways
+					},
+				},
+				{
+					shutdown: noopShutdown,
+					callback: func(ctx context.Context) error {
+						close(started)
+						<-ctx.Done()
+						<-release // ignores shutdown budget
+						return nil
+					},
+				},
+			},
+		})
+	}
+
 	for i := range cases {
 		tt := cases[i]
 
@@ -176,8 +255,322 @@ func TestNew(t *testing.T) {
 				run.Add(tt.services[j].callback, tt.services[j].shutdown)
 			}
 
-			require.Equal(t, tt.expect, run.Run(tt.ctx))
+			err := run.Run(tt.ctx)
+			if tt.expect == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.expect)
+			}
+
+			for _, expect := range tt.also {
+				require.ErrorIs(t, err, expect)
+			}
+
 			require.InDelta(t, time.Since(now), time.Millisecond*5, float64(time.Millisecond*10)) // 10ms lags
 		})
 	}
 }
+
+func TestOrder(t *testing.T) {
+	t.Run("should start services after dependencies are up", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			events []string
+		)
+
+		record := func(v string) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			events = append(events, v)
+		}
+
+		// service returns only after its shutdown was called,
+		// so "done" shows that the service has fully returned before its dependencies are stopped
+		worker := func(name string) (Callback, Shutdown) {
+			stopped := make(chan struct{})
+
+			return func(ctx context.Context) error {
+					record("start " + name)
+
+					NotifyReady(ctx)
+
+					<-ctx.Done()
+					<-stopped
+
+					record("done " + name)
+
+					return nil
+				},
+				func(context.Context) error {
+					record("stop " + name)
+					close(stopped)
+
+					return nil
+				}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		run := New(WithShutdownTimeout(defaultAwait))
+
+		{ // services are added in reverse order
+			callback, shutdown := worker("api")
+			run.Add(callback, shutdown, Name("api"), After("cache"), StartPhase(1))
+		}
+
+		{
+			callback, shutdown := worker("cache")
+			run.Add(callback, shutdown, Name("cache"), After("migrator"), WaitReady())
+		}
+
+		{
+			callback, shutdown := worker("migrator")
+			run.Add(callback, shutdown, Name("migrator"), WaitReady())
+		}
+
+		go func() {
+			<-time.After(defaultAwait)
+
+			cancel()
+		}()
+
+		require.NoError(t, run.Run(ctx))
+		require.Equal(t, []string{
+			"start migrator",
+			"start cache",
+			"start api",
+			"stop api",
+			"done api",
+			"stop cache",
+			"done cache",
+			"stop migrator",
+			"done migrator",
+		}, events)
+	})
+
+	t.Run("should not start services until dependency is ready", func(t *testing.T) {
+		started := atomic.NewBool(false)
+
+		run := New(WithShutdownTimeout(defaultAwait))
+		run.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown, Name("never-ready"), WaitReady())
+
+		run.Add(func(context.Context) error {
+			started.Store(true)
+
+			return nil
+		}, noopShutdown, After("never-ready"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAwait)
+		defer cancel()
+
+		require.NoError(t, run.Run(ctx))
+		require.False(t, started.Load())
+	})
+
+	t.Run("should fail on dependency cycle", func(t *testing.T) {
+		noop := noopShutdown
+		callback := func(context.Context) error { return nil }
+
+		err := New().
+			Add(callback, noop, Name("first"), After("second")).
+			Add(callback, noop, Name("second"), After("first")).
+			Run(context.Background())
+
+		require.ErrorIs(t, err, ErrDependencyCycle)
+		require.EqualError(t, err, `dependency cycle: "first" -> "second" -> "first"`)
+	})
+
+	t.Run("should fail on unknown dependency", func(t *testing.T) {
+		err := New().
+			Add(func(context.Context) error { return nil }, noopShutdown, Name("first"), After("unknown")).
+			Run(context.Background())
+
+		require.ErrorIs(t, err, ErrUnknownDependency)
+	})
+}
+
+func TestReady(t *testing.T) {
+	t.Run("should be ready when all services are up", func(t *testing.T) {
+		run := New(WithShutdownTimeout(defaultAwait))
+		release := make(chan struct{})
+
+		run.Add(func(ctx context.Context) error {
+			<-release
+			NotifyReady(ctx)
+
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown, WaitReady())
+
+		run.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() { done <- run.Run(ctx) }()
+
+		select {
+		case <-run.Ready():
+			t.Fatal("group should not be ready")
+		case <-time.After(defaultAwait):
+		}
+
+		close(release)
+		<-run.Ready()
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("empty group should be ready", func(t *testing.T) {
+		run := New()
+		require.NoError(t, run.Run(context.Background()))
+		<-run.Ready()
+	})
+}
+
+func TestSupervisorMode(t *testing.T) {
+	t.Run("should restart service and stop group when budget is exhausted", func(t *testing.T) {
+		calls := atomic.NewInt32(0)
+
+		run := New(WithShutdownTimeout(defaultAwait), WithRestart(RestartConfig{
+			Policy:      RestartOnFailure,
+			MinBackoff:  time.Millisecond,
+			MaxRestarts: 3,
+		}))
+
+		run.Add(func(context.Context) error {
+			calls.Inc()
+
+			return errAlways
+		}, noopShutdown)
+
+		run.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}, noopShutdown)
+
+		err := run.Run(context.Background())
+		require.ErrorIs(t, err, ErrRestartsExhausted)
+		require.ErrorIs(t, err, errAlways)
+		require.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("service policy should override group policy", func(t *testing.T) {
+		calls := atomic.NewInt32(0)
+
+		run := New(WithShutdownTimeout(defaultAwait), WithRestart(RestartConfig{Policy: RestartAlways}))
+		run.Add(func(context.Context) error {
+			calls.Inc()
+
+			return errAlways
+		}, noopShutdown, Restart(RestartConfig{Policy: RestartNever}))
+
+		require.ErrorIs(t, run.Run(context.Background()), errAlways)
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("should not restart stopped service", func(t *testing.T) {
+		calls := atomic.NewInt32(0)
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAwait)
+		defer cancel()
+
+		run := New(WithShutdownTimeout(defaultAwait))
+		run.Add(func(ctx context.Context) error {
+			calls.Inc()
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown, Restart(RestartConfig{Policy: RestartAlways}))
+
+		require.NoError(t, run.Run(ctx))
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("should fail on unknown restart policy", func(t *testing.T) {
+		run := New(WithRestart(RestartConfig{Policy: "unknown"}))
+		run.Add(func(context.Context) error { return nil }, noopShutdown)
+		require.ErrorIs(t, run.Run(context.Background()), ErrUnknownRestartPolicy)
+
+		run = New()
+		run.Add(func(context.Context) error { return nil }, noopShutdown,
+			Restart(RestartConfig{Policy: "unknown"}))
+		require.ErrorIs(t, run.Run(context.Background()), ErrUnknownRestartPolicy)
+	})
+
+	t.Run("should fail on services with the same name", func(t *testing.T) {
+		run := New()
+		run.Add(func(context.Context) error { return nil }, noopShutdown, Name("worker"))
+		run.Add(func(context.Context) error { return nil }, noopShutdown, Name("worker"))
+		require.ErrorIs(t, run.Run(context.Background()), ErrServiceExists)
+
+		// services without name are not checked
+		run = New()
+		run.Add(func(context.Context) error { return nil }, noopShutdown)
+		run.Add(func(context.Context) error { return nil }, noopShutdown)
+		require.NoError(t, run.Run(context.Background()))
+	})
+}
+
+func TestOptional(t *testing.T) {
+	t.Run("should not stop the group when optional service fails", func(t *testing.T) {
+		var (
+			failed  = make(chan string, 1)
+			ctx, cl = context.WithCancel(context.Background())
+		)
+
+		run := New(WithShutdownTimeout(defaultAwait), WithFailureHandler(func(name string, err error) {
+			require.ErrorIs(t, err, errAlways)
+
+			failed <- name
+		}))
+
+		run.Add(func(context.Context) error { return errAlways }, noopShutdown,
+			Name("optional"), Optional())
+
+		run.Add(func(ctx context.Context) error {
+			select {
+			case name := <-failed:
+				require.Equal(t, "optional", name)
+			case <-ctx.Done():
+				t.Fatal("service should not be stopped")
+			}
+
+			cl()
+
+			<-ctx.Done()
+
+			return ctx.Err()
+		}, noopShutdown)
+
+		require.NoError(t, run.Run(ctx))
+	})
+
+	t.Run("should not report stopped optional service", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAwait)
+		defer cancel()
+
+		run := New(WithShutdownTimeout(defaultAwait), WithFailureHandler(func(string, error) {
+			t.Fatal("should not be called")
+		}))
+
+		run.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return errAlways
+		}, noopShutdown, Optional())
+
+		require.NoError(t, run.Run(ctx))
+	})
+}
@@ -7,7 +7,8 @@ import (
 // Option allows to change group settings.
 type Option func(*group)
 
-// WithShutdownTimeout allows to change shutdown shutdown.
+// WithShutdownTimeout allows to change default shutdown timeout of services.
+// Every service has its own budget, that starts when the service is stopping (see ShutdownTimeout).
 func WithShutdownTimeout(v time.Duration) Option {
 	return func(g *group) {
 		if v == 0 {
@@ -18,7 +19,78 @@ func WithShutdownTimeout(v time.Duration) Option {
 	}
 }
 
-// WithIgnoreErrors allows to add ignored errors.
+// WithIgnoreErrors allows to add ignored errors, ErrShutdownTimeout could be ignored too.
 func WithIgnoreErrors(v ...error) Option {
 	return func(g *group) { g.ignore = append(g.ignore, v...) }
 }
+
+// WithRestart sets default restart policy for all services of the group.
+// By default, services are never restarted.
+func WithRestart(v RestartConfig) Option {
+	return func(g *group) { g.restart = v }
+}
+
+// WithFailureHandler sets handler that will be called when optional service fails.
+func WithFailureHandler(v FailureHandler) Option {
+	return func(g *group) { g.failure = v }
+}
+
+// WithPanicRecovery allows to enable or disable recovery of services panics.
+// By default, panics are recovered and returned as PanicError,
+// when recovery is disabled panic of any service crashes the application.
+func WithPanicRecovery(v bool) Option {
+	return func(g *group) { g.recovery = v }
+}
+
+// WithPanicHandler sets handler that will be called when panic of the service is recovered.
+func WithPanicHandler(v PanicHandler) Option {
+	return func(g *group) { g.panics = v }
+}
+
+// WithShutdownReporter sets reporter that will be called when all services are stopped or abandoned.
+func WithShutdownReporter(v ShutdownReporter) Option {
+	return func(g *group) { g.report = v }
+}
+
+// ServiceOption allows to change service settings.
+type ServiceOption func(*service)
+
+// Name sets name of the service, that can be used by other services as dependency.
+func Name(v string) ServiceOption {
+	return func(s *service) { s.name = v }
+}
+
+// After allows to declare services (by name) that should be up before the service starts.
+func After(names ...string) ServiceOption {
+	return func(s *service) { s.after = append(s.after, names...) }
+}
+
+// StartPhase sets start phase of the service.
+// Services of the phase will be started when all services of previous phases are up.
+// By default, all services have zero phase.
+func StartPhase(v int) ServiceOption {
+	return func(s *service) { s.phase = v }
+}
+
+// WaitReady allows service to report when it is up by calling NotifyReady.
+// By default, service is considered up right after the callback is called.
+func WaitReady() ServiceOption {
+	return func(s *service) { s.waitReady = true }
+}
+
+// ShutdownTimeout sets shutdown timeout of the service, it overrides default shutdown timeout of the group.
+// Service that was not stopped in time is abandoned and reported as timed out.
+func ShutdownTimeout(v time.Duration) ServiceOption {
+	return func(s *service) { s.shutdownTimeout = v }
+}
+
+// Restart sets restart policy for the service, it overrides default restart policy of the group.
+func Restart(v RestartConfig) ServiceOption {
+	return func(s *service) { s.restart = &v }
+}
+
+// Optional marks service as optional. When optional service returns,
+// the error is passed to FailureHandler and other services of the group keep running.
+func Optional() ServiceOption {
+	return func(s *service) { s.optional = true }
+}
@@ -0,0 +1,138 @@
+package group
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+const (
+	// ErrUnknownDependency is raised when service depends on a service that was not added to the group.
+	ErrUnknownDependency = internal.Error("unknown dependency")
+
+	// ErrDependencyCycle is raised when services have circular dependencies.
+	ErrDependencyCycle = internal.Error("dependency cycle")
+)
+
+const (
+	unvisited = iota
+	visiting
+	visited
+)
+
+// dependencies returns indexes of services that every service should wait for.
+// Services of start phase depend on all services of the previous start phase.
+func dependencies(services []service) ([][]int, error) {
+	names := make(map[string][]int, len(services))
+	phases := make(map[int][]int)
+
+	for i := range services {
+		if services[i].name != "" {
+			names[services[i].name] = append(names[services[i].name], i)
+		}
+
+		phases[services[i].phase] = append(phases[services[i].phase], i)
+	}
+
+	order := make([]int, 0, len(phases))
+	for phase := range phases {
+		order = append(order, phase)
+	}
+
+	sort.Ints(order)
+
+	previous := make(map[int][]int, len(order))
+	for i := 1; i < len(order); i++ {
+		previous[order[i]] = phases[order[i-1]]
+	}
+
+	result := make([][]int, len(services))
+	for i := range services {
+		for _, name := range services[i].after {
+			idx, ok := names[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: service %q depends on %q",
+					ErrUnknownDependency, services[i].name, name)
+			}
+
+			result[i] = append(result[i], idx...)
+		}
+
+		result[i] = append(result[i], previous[services[i].phase]...)
+	}
+
+	return result, nil
+}
+
+// levels splits services into start levels.
+// Services of every level depend only on services of previous levels.
+func levels(services []service, deps [][]int) ([][]int, error) {
+	var (
+		depth = make([]int, len(deps))
+		state = make([]int, len(deps))
+		visit func(int, []int) error
+	)
+
+	visit = func(idx int, path []int) error {
+		switch state[idx] {
+		case visited:
+			return nil
+		case visiting:
+			return cycleError(services, append(path, idx))
+		}
+
+		state[idx] = visiting
+		for _, dep := range deps[idx] {
+			if err := visit(dep, append(path, idx)); err != nil {
+				return err
+			}
+
+			if depth[dep]+1 > depth[idx] {
+				depth[idx] = depth[dep] + 1
+			}
+		}
+
+		state[idx] = visited
+
+		return nil
+	}
+
+	var result [][]int
+	for i := range deps {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+
+		for len(result) <= depth[i] {
+			result = append(result, nil)
+		}
+	}
+
+	for i := range depth {
+		result[depth[i]] = append(result[depth[i]], i)
+	}
+
+	return result, nil
+}
+
+func cycleError(services []service, path []int) error {
+	last := path[len(path)-1]
+
+	// trim path to the beginning of the cycle
+	for i := range path {
+		if path[i] == last {
+			path = path[i:]
+
+			break
+		}
+	}
+
+	names := make([]string, 0, len(path))
+	for _, idx := range path {
+		names = append(names, fmt.Sprintf("%q", services[idx].name))
+	}
+
+	return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(names, " -> "))
+}
@@ -0,0 +1,90 @@
+package group
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevels(t *testing.T) {
+	cases := []struct {
+		name     string
+		services []service
+		expect   [][]int
+		error    error
+	}{
+		{
+			name:     "without dependencies",
+			services: []service{{name: "first"}, {name: "second"}},
+			expect:   [][]int{{0, 1}},
+		},
+
+		{
+			name: "dependencies by name",
+			services: []service{
+				{name: "first", after: []string{"second"}},
+				{name: "second", after: []string{"third"}},
+				{name: "third"},
+				{name: "fourth", after: []string{"third"}},
+			},
+			expect: [][]int{{2}, {1, 3}, {0}},
+		},
+
+		{
+			name: "dependencies by phases",
+			services: []service{
+				{name: "first", phase: 2},
+				{name: "second", phase: 1},
+				{name: "third", phase: 1},
+				{name: "fourth"},
+			},
+			expect: [][]int{{3}, {1, 2}, {0}},
+		},
+
+		{
+			name: "duplicated names",
+			services: []service{
+				{name: "first", after: []string{"second"}},
+				{name: "second"},
+				{name: "second"},
+			},
+			expect: [][]int{{1, 2}, {0}},
+		},
+
+		{
+			name:     "self dependency",
+			services: []service{{name: "first", after: []string{"first"}}},
+			error:    ErrDependencyCycle,
+		},
+
+		{
+			name: "cycle by phases",
+			services: []service{
+				{name: "first", phase: 1},
+				{name: "second", after: []string{"first"}},
+			},
+			error: ErrDependencyCycle,
+		},
+
+		{
+			name:     "unknown dependency",
+			services: []service{{name: "first", after: []string{"second"}}},
+			error:    ErrUnknownDependency,
+		},
+	}
+
+	for i := range cases {
+		tt := cases[i]
+
+		t.Run(tt.name, func(t *testing.T) {
+			deps, err := dependencies(tt.services)
+			if err == nil {
+				var res [][]int
+				res, err = levels(tt.services, deps)
+				require.Equal(t, tt.expect, res)
+			}
+
+			require.ErrorIs(t, err, tt.error)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package group
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+type (
+	// PanicError is returned when service callback or shutdown function panics.
+	// It contains the name of the service, the panic value and the stack trace.
+	PanicError struct {
+		Service string
+		Value   interface{}
+		Stack   []byte
+	}
+
+	// PanicHandler is called when panic of the service is recovered.
+	PanicHandler func(*PanicError)
+)
+
+var _ error = (*PanicError)(nil)
+
+// Error returns error message as string.
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// Unwrap returns panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// protect calls passed function and converts panic into PanicError when recovery is enabled.
+func (g *group) protect(name string, fn func() error) (err error) {
+	if !g.recovery {
+		return fn()
+	}
+
+	defer func() {
+		val := recover()
+		if val == nil {
+			return
+		}
+
+		perr := &PanicError{Service: name, Value: val, Stack: debug.Stack()}
+		if g.panics != nil {
+			g.panics(perr)
+		}
+
+		err = perr
+	}()
+
+	return fn()
+}
@@ -0,0 +1,68 @@
+package group
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	t.Run("should recover panic of callback and shutdown", func(t *testing.T) {
+		var handled []string
+
+		run := New(WithShutdownTimeout(defaultAwait), WithPanicHandler(func(err *PanicError) {
+			handled = append(handled, err.Service)
+
+			require.NotEmpty(t, err.Stack)
+		}))
+
+		run.Add(func(context.Context) error { panic("boom") },
+			func(context.Context) error { panic(errAlways) },
+			Name("panicked"))
+
+		err := run.Run(context.Background())
+
+		var errs Errors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 2)
+		require.Equal(t, []string{"panicked", "panicked"}, handled)
+
+		var perr *PanicError
+		require.ErrorAs(t, errs[0], &perr)
+		require.Equal(t, PhaseStart, errs[0].Phase)
+		require.Equal(t, "boom", perr.Value)
+		require.Equal(t, "panicked", perr.Service)
+		require.Contains(t, string(perr.Stack), "panic_test.go")
+		require.EqualError(t, errs[0], `service "panicked" start: panic: boom`)
+
+		// panic value should be unwrapped
+		require.Equal(t, PhaseStop, errs[1].Phase)
+		require.ErrorIs(t, errs[1], errAlways)
+	})
+
+	t.Run("should restart panicked service", func(t *testing.T) {
+		calls := 0
+
+		run := New(WithShutdownTimeout(defaultAwait))
+		run.Add(func(context.Context) error {
+			if calls++; calls == 1 {
+				panic("boom")
+			}
+
+			return errAlways
+		}, noopShutdown, Restart(RestartConfig{Policy: RestartOnFailure, MaxRestarts: 1}))
+
+		require.ErrorIs(t, run.Run(context.Background()), ErrRestartsExhausted)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("should not recover when recovery is disabled", func(t *testing.T) {
+		run, ok := New(WithPanicRecovery(false)).(*group)
+		require.True(t, ok)
+
+		require.Panics(t, func() {
+			_ = run.protect("panicked", func() error { panic("boom") })
+		})
+	})
+}
@@ -0,0 +1,158 @@
+package group
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+type (
+	// RestartPolicy defines when service should be restarted after its callback returns.
+	RestartPolicy string
+
+	// RestartConfig allows to run service in supervisor mode.
+	// When callback returns, service will be restarted according to the policy
+	// with exponential backoff and jitter. When service was restarted more than MaxRestarts
+	// times within Window, the whole group will be stopped.
+	RestartConfig struct {
+		Policy      RestartPolicy `mapstructure:"policy"`
+		MinBackoff  time.Duration `mapstructure:"min_backoff"`
+		MaxBackoff  time.Duration `mapstructure:"max_backoff"`
+		MaxRestarts int           `mapstructure:"max_restarts"`
+		Window      time.Duration `mapstructure:"window"`
+	}
+
+	// supervisor tracks restarts of the service.
+	supervisor struct {
+		RestartConfig
+
+		history []time.Time
+	}
+
+	restartError struct {
+		restarts int
+		reason   error
+	}
+)
+
+const (
+	// RestartNever never restarts service, when service returns the whole group will be stopped.
+	RestartNever RestartPolicy = "never"
+
+	// RestartOnFailure restarts service only when it returns an error.
+	RestartOnFailure RestartPolicy = "on-failure"
+
+	// RestartAlways restarts service whenever it returns.
+	RestartAlways RestartPolicy = "always"
+)
+
+const (
+	// ErrUnknownRestartPolicy is raised when service has unknown restart policy.
+	ErrUnknownRestartPolicy = internal.Error("unknown restart policy")
+
+	// ErrRestartsExhausted is raised when service was restarted too many times within the window.
+	ErrRestartsExhausted = internal.Error("restarts exhausted")
+)
+
+const (
+	defaultMinBackoff  = time.Millisecond * 100
+	defaultMaxBackoff  = time.Second * 10
+	defaultMaxRestarts = 5
+	defaultWindow      = time.Minute
+)
+
+// Error returns error message as string.
+func (e *restartError) Error() string {
+	return fmt.Sprintf("%s after %d restarts: %v", ErrRestartsExhausted, e.restarts, e.reason)
+}
+
+// Unwrap returns the last error of the service.
+func (e *restartError) Unwrap() error { return e.reason }
+
+// Is allows to check that error is ErrRestartsExhausted.
+func (e *restartError) Is(target error) bool { return target == ErrRestartsExhausted }
+
+func (c RestartConfig) validate() error {
+	switch c.Policy {
+	case "", RestartNever, RestartOnFailure, RestartAlways:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownRestartPolicy, c.Policy)
+	}
+}
+
+// withDefaults returns config with default values for empty fields.
+func (c RestartConfig) withDefaults() RestartConfig {
+	if c.Policy == "" {
+		c.Policy = RestartNever
+	}
+
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = defaultMinBackoff
+	}
+
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+
+	if c.MaxRestarts <= 0 {
+		c.MaxRestarts = defaultMaxRestarts
+	}
+
+	if c.Window <= 0 {
+		c.Window = defaultWindow
+	}
+
+	return c
+}
+
+func newSupervisor(cfg RestartConfig) *supervisor {
+	return &supervisor{RestartConfig: cfg.withDefaults()}
+}
+
+// restart checks that service should be restarted after callback returned passed error.
+// It returns backoff delay before the next restart of the service or false
+// and the result of the service when service should not be restarted anymore.
+func (s *supervisor) restart(err error, now time.Time) (time.Duration, bool, error) {
+	switch {
+	case s.Policy == RestartNever:
+		return 0, false, err
+	case s.Policy == RestartOnFailure && err == nil:
+		return 0, false, nil
+	}
+
+	// forget restarts that are out of the window
+	actual := s.history[:0]
+	for _, last := range s.history {
+		if now.Sub(last) < s.Window {
+			actual = append(actual, last)
+		}
+	}
+
+	if s.history = actual; len(s.history) >= s.MaxRestarts {
+		return 0, false, &restartError{restarts: len(s.history), reason: err}
+	}
+
+	s.history = append(s.history, now)
+
+	return s.backoff(len(s.history) - 1), true, nil
+}
+
+// backoff returns exponential delay with equal jitter for passed attempt.
+func (s *supervisor) backoff(attempt int) time.Duration {
+	delay := s.MaxBackoff
+	if attempt < 32 && s.MinBackoff<<attempt < s.MaxBackoff {
+		delay = s.MinBackoff << attempt
+	}
+
+	half := int64(delay / 2)
+
+	// nolint:gosec
+	return time.Duration(half + rand.Int63n(half+1))
+}
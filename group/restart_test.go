@@ -0,0 +1,89 @@
+package group
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisor(t *testing.T) {
+	t.Run("should not restart by default", func(t *testing.T) {
+		super := newSupervisor(RestartConfig{})
+
+		_, ok, err := super.restart(errAlways, time.Now())
+		require.False(t, ok)
+		require.ErrorIs(t, err, errAlways)
+	})
+
+	t.Run("should restart only on failure", func(t *testing.T) {
+		super := newSupervisor(RestartConfig{Policy: RestartOnFailure})
+
+		_, ok, err := super.restart(nil, time.Now())
+		require.False(t, ok)
+		require.NoError(t, err)
+
+		_, ok, err = super.restart(errAlways, time.Now())
+		require.True(t, ok)
+		require.NoError(t, err)
+	})
+
+	t.Run("should always restart until budget is exhausted", func(t *testing.T) {
+		now := time.Now()
+		super := newSupervisor(RestartConfig{
+			Policy:      RestartAlways,
+			MaxRestarts: 2,
+			Window:      time.Minute,
+		})
+
+		for i := 0; i < 2; i++ {
+			_, ok, err := super.restart(nil, now)
+			require.True(t, ok)
+			require.NoError(t, err)
+		}
+
+		_, ok, err := super.restart(errAlways, now)
+		require.False(t, ok)
+		require.ErrorIs(t, err, ErrRestartsExhausted)
+		require.ErrorIs(t, err, errAlways)
+
+		// restarts out of the window should be forgotten
+		_, ok, err = super.restart(errAlways, now.Add(time.Minute))
+		require.True(t, ok)
+		require.NoError(t, err)
+	})
+
+	t.Run("should grow backoff exponentially with jitter", func(t *testing.T) {
+		super := newSupervisor(RestartConfig{
+			MinBackoff: time.Millisecond,
+			MaxBackoff: time.Millisecond * 8,
+		})
+
+		for attempt, expect := range []time.Duration{1, 2, 4, 8, 8, 8} {
+			expect *= time.Millisecond
+
+			delay := super.backoff(attempt)
+			require.GreaterOrEqual(t, delay, expect/2)
+			require.LessOrEqual(t, delay, expect)
+		}
+
+		// should not overflow
+		require.LessOrEqual(t, super.backoff(100), time.Millisecond*8)
+	})
+
+	t.Run("should set defaults", func(t *testing.T) {
+		require.Equal(t, RestartConfig{
+			Policy:      RestartNever,
+			MinBackoff:  defaultMinBackoff,
+			MaxBackoff:  defaultMaxBackoff,
+			MaxRestarts: defaultMaxRestarts,
+			Window:      defaultWindow,
+		}, RestartConfig{}.withDefaults())
+
+		require.Equal(t, time.Minute, RestartConfig{MinBackoff: time.Minute}.withDefaults().MaxBackoff)
+	})
+
+	t.Run("should fail on unknown policy", func(t *testing.T) {
+		require.ErrorIs(t, RestartConfig{Policy: "unknown"}.validate(), ErrUnknownRestartPolicy)
+	})
+}
@@ -0,0 +1,206 @@
+package group
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+const (
+	// ErrGroupStopped is raised when services are attached or detached after the group was stopped.
+	ErrGroupStopped = internal.Error("group is stopped")
+
+	// ErrUnknownService is raised when detached service was not found.
+	ErrUnknownService = internal.Error("unknown service")
+
+	// ErrServiceExists is raised when attached service has the same name as the service of the group.
+	ErrServiceExists = internal.Error("service already exists")
+
+	// ErrServiceHasDependents is raised when detached service is a dependency of other services.
+	ErrServiceHasDependents = internal.Error("service has dependents")
+)
+
+// Attach an service (callback and shutdown) to the group.
+// - before Run it works like Add, but it returns ErrServiceExists instead of Run.
+// - when the group is running, service starts right after its dependencies are up,
+// dependencies (see After) should be attached to the group before the service.
+// - attached service is stopped with other services or by Detach.
+func (g *group) Attach(callback Callback, stopper Shutdown, options ...ServiceOption) error {
+	svc := newService(callback, stopper, options...)
+	if svc.restart != nil {
+		if err := svc.restart.validate(); err != nil {
+			return fmt.Errorf("service %q: %w", svc.name, err)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case g.stopping:
+		return ErrGroupStopped
+	case svc.name != "" && g.exists(svc.name):
+		return fmt.Errorf("%w: %q", ErrServiceExists, svc.name)
+	case !g.running:
+		g.services = append(g.services, svc)
+
+		return nil
+	}
+
+	u := g.newUnit(g.ctx, svc)
+	for _, name := range svc.after {
+		deps := g.find(name)
+		if len(deps) == 0 {
+			return fmt.Errorf("%w: service %q depends on %q", ErrUnknownDependency, svc.name, name)
+		}
+
+		u.deps = append(u.deps, deps...)
+	}
+
+	// service of the start phase depends on services of previous start phases
+	for _, dep := range g.units {
+		if dep.phase < svc.phase {
+			u.deps = append(u.deps, dep)
+		}
+	}
+
+	g.services = append(g.services, svc)
+	g.units = append(g.units, u)
+
+	go u.run()
+
+	return nil
+}
+
+// Detach gracefully stops services (found by name) of the running group and removes them from the group.
+// Passed context limits the time to stop services, besides their shutdown timeouts.
+// It returns Errors that contain start, stop and timeout errors of detached services.
+// Services that are dependencies of other services and services without name could not be detached.
+func (g *group) Detach(ctx context.Context, name string) error {
+	g.mu.Lock()
+
+	if g.stopping {
+		g.mu.Unlock()
+
+		return ErrGroupStopped
+	}
+
+	// services without name could not be found, so they could not be detached
+	if name == "" || !g.exists(name) {
+		g.mu.Unlock()
+
+		return fmt.Errorf("%w: %q", ErrUnknownService, name)
+	}
+
+	var detached, units []*unit
+
+	for _, u := range g.units {
+		if u.name == name {
+			detached = append(detached, u)
+
+			continue
+		}
+
+		units = append(units, u)
+	}
+
+	for _, u := range units {
+		for _, dep := range u.deps {
+			if dep.name == name {
+				g.mu.Unlock()
+
+				return fmt.Errorf("%w: service %q depends on %q", ErrServiceHasDependents, u.name, name)
+			}
+		}
+	}
+
+	services := make([]service, 0, len(g.services))
+	for i := range g.services {
+		if g.services[i].name != name {
+			services = append(services, g.services[i])
+		}
+	}
+
+	g.units = units
+	g.services = services
+
+	// group should wait until detached services will be stopped
+	g.detaching.Add(1)
+	g.mu.Unlock()
+
+	defer g.detaching.Done()
+
+	results := make([]ShutdownResult, 0, len(detached))
+	for _, u := range detached {
+		results = append(results, u.stop(ctx))
+	}
+
+	if len(results) > 0 && g.report != nil {
+		g.report(results)
+	}
+
+	return g.collect(detached).Err()
+}
+
+// exists checks that the group contains the service with passed name, it should be called under lock.
+func (g *group) exists(name string) bool {
+	for i := range g.services {
+		if g.services[i].name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// find returns running units of services by name, it should be called under lock.
+func (g *group) find(name string) []*unit {
+	var result []*unit
+
+	for _, u := range g.units {
+		if u.name == name {
+			result = append(result, u)
+		}
+	}
+
+	return result
+}
+
+// stages splits units into start stages, units of every stage depend only on units of previous stages.
+// It returns indexes of units, units should not have circular dependencies.
+func stages(units []*unit) [][]int {
+	var (
+		depth = make(map[*unit]int, len(units))
+		visit func(*unit) int
+	)
+
+	visit = func(u *unit) int {
+		if d, ok := depth[u]; ok {
+			return d
+		}
+
+		d := 0
+		for _, dep := range u.deps {
+			if next := visit(dep) + 1; next > d {
+				d = next
+			}
+		}
+
+		depth[u] = d
+
+		return d
+	}
+
+	var result [][]int
+	for i := range units {
+		d := visit(units[i])
+		for len(result) <= d {
+			result = append(result, nil)
+		}
+
+		result[d] = append(result[d], i)
+	}
+
+	return result
+}
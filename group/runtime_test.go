@@ -0,0 +1,165 @@
+package group
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestAttachDetach(t *testing.T) {
+	worker := func(started *atomic.Int32) Callback {
+		return func(ctx context.Context) error {
+			started.Inc()
+			<-ctx.Done()
+
+			return nil
+		}
+	}
+
+	t.Run("should attach and detach services of running group", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			stopped []string
+			started = atomic.NewInt32(0)
+		)
+
+		shutdown := func(name string) Shutdown {
+			return func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				stopped = append(stopped, name)
+
+				return nil
+			}
+		}
+
+		grp := New()
+		grp.Add(worker(started), shutdown("main"), Name("main"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() { done <- grp.Run(ctx) }()
+
+		<-grp.Ready()
+
+		require.NoError(t, grp.Attach(worker(started), shutdown("tenant-1"), Name("tenant-1"), After("main")))
+		require.NoError(t, grp.Attach(worker(started), shutdown("tenant-2"), Name("tenant-2")))
+		require.Eventually(t, func() bool { return started.Load() == 3 }, time.Second, time.Millisecond)
+		require.Len(t, grp.States(), 3)
+
+		require.NoError(t, grp.Detach(context.Background(), "tenant-1"))
+		require.Len(t, grp.States(), 2)
+
+		mu.Lock()
+		require.Equal(t, []string{"tenant-1"}, stopped)
+		mu.Unlock()
+
+		// group should keep running
+		select {
+		case err := <-done:
+			t.Fatalf("group should not be stopped: %v", err)
+		case <-time.After(defaultAwait):
+		}
+
+		cancel()
+		require.NoError(t, <-done)
+
+		mu.Lock()
+		require.ElementsMatch(t, []string{"tenant-1", "tenant-2", "main"}, stopped)
+		mu.Unlock()
+
+		require.ErrorIs(t, grp.Attach(worker(started), noopShutdown, Name("late")), ErrGroupStopped)
+		require.ErrorIs(t, grp.Detach(context.Background(), "main"), ErrGroupStopped)
+	})
+
+	t.Run("should return errors of detached service", func(t *testing.T) {
+		var report []ShutdownResult
+
+		grp := New(WithShutdownReporter(func(v []ShutdownResult) { report = v }))
+		grp.Add(worker(atomic.NewInt32(0)), noopShutdown, Name("main"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() { _ = grp.Run(ctx) }()
+
+		<-grp.Ready()
+
+		started := atomic.NewInt32(0)
+		require.NoError(t, grp.Attach(worker(started), func(context.Context) error {
+			return errAlways
+		}, Name("tenant")))
+		require.Eventually(t, func() bool { return started.Load() == 1 }, time.Second, time.Millisecond)
+
+		err := grp.Detach(context.Background(), "tenant")
+		require.ErrorIs(t, err, errAlways)
+
+		var list Errors
+		require.ErrorAs(t, err, &list)
+		require.Equal(t, "tenant", list[0].Service)
+		require.Equal(t, PhaseStop, list[0].Phase)
+
+		require.Len(t, report, 1)
+		require.Equal(t, "tenant", report[0].Service)
+	})
+
+	t.Run("should validate attached and detached services", func(t *testing.T) {
+		grp := New()
+		grp.Add(worker(atomic.NewInt32(0)), noopShutdown, Name("main"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() { done <- grp.Run(ctx) }()
+
+		<-grp.Ready()
+
+		require.ErrorIs(t, grp.Attach(worker(atomic.NewInt32(0)), noopShutdown, Name("main")), ErrServiceExists)
+		require.ErrorIs(t, grp.Attach(worker(atomic.NewInt32(0)), noopShutdown, After("unknown")), ErrUnknownDependency)
+		require.ErrorIs(t, grp.Attach(worker(atomic.NewInt32(0)), noopShutdown,
+			Restart(RestartConfig{Policy: "unknown"})), ErrUnknownRestartPolicy)
+
+		require.NoError(t, grp.Attach(worker(atomic.NewInt32(0)), noopShutdown, Name("tenant"), After("main")))
+		require.ErrorIs(t, grp.Detach(context.Background(), "main"), ErrServiceHasDependents)
+		require.ErrorIs(t, grp.Detach(context.Background(), "unknown"), ErrUnknownService)
+
+		// services without name could not be detached
+		require.NoError(t, grp.Attach(worker(atomic.NewInt32(0)), noopShutdown))
+		require.ErrorIs(t, grp.Detach(context.Background(), ""), ErrUnknownService)
+		require.Len(t, grp.States(), 3)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("attached service should stop the group when it returns", func(t *testing.T) {
+		grp := New()
+		grp.Add(worker(atomic.NewInt32(0)), noopShutdown, Name("main"))
+
+		done := make(chan error)
+
+		go func() { done <- grp.Run(context.Background()) }()
+
+		<-grp.Ready()
+
+		require.NoError(t, grp.Attach(func(context.Context) error { return errAlways }, noopShutdown))
+		require.ErrorIs(t, <-done, errAlways)
+	})
+
+	t.Run("should attach and detach services before run", func(t *testing.T) {
+		grp := New()
+		require.NoError(t, grp.Attach(worker(atomic.NewInt32(0)), noopShutdown, Name("first")))
+		require.NoError(t, grp.Attach(worker(atomic.NewInt32(0)), noopShutdown, Name("second")))
+		require.NoError(t, grp.Detach(context.Background(), "second"))
+
+		states := grp.States()
+		require.Len(t, states, 1)
+		require.Equal(t, "first", states[0].Name)
+	})
+}
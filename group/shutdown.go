@@ -0,0 +1,94 @@
+package group
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// ShutdownResult describes how the service was stopped.
+	ShutdownResult struct {
+		// Service is the name of the service.
+		Service string
+
+		// Budget is the shutdown timeout of the service.
+		Budget time.Duration
+
+		// Duration is the time spent to stop the service.
+		Duration time.Duration
+
+		// Abandoned is true when service was not stopped in time.
+		Abandoned bool
+	}
+
+	// ShutdownReporter is called when all services are stopped or abandoned and when services are detached.
+	// It receives results of stopped services in order of registration.
+	ShutdownReporter func([]ShutdownResult)
+)
+
+// budget returns shutdown timeout of the service.
+func (u *unit) budget() time.Duration {
+	if u.shutdownTimeout > 0 {
+		return u.shutdownTimeout
+	}
+
+	return u.owner.shutdown
+}
+
+// stop cancels service context, calls shutdown function
+// if service was started and waits until service returns.
+// Every service has its own shutdown budget, that starts when the service is stopping.
+// Service that was not stopped in time is marked as timed out and abandoned.
+func (u *unit) stop(parent context.Context) ShutdownResult {
+	budget := u.budget()
+	start := time.Now()
+
+	u.status.set(StateStopping, nil)
+
+	ctx, cancel := context.WithTimeout(parent, budget)
+	defer cancel()
+
+	u.mu.Lock()
+	u.cancel()
+	started := u.started
+	u.mu.Unlock()
+
+	result := make(chan error, 1)
+
+	go func() {
+		var err error
+		if started {
+			err = u.owner.protect(u.name, func() error { return u.shutdown(ctx) })
+		}
+
+		<-u.done
+
+		result <- err
+	}()
+
+	select {
+	case u.stopErr = <-result:
+	case <-ctx.Done():
+		select {
+		case u.stopErr = <-result: // service has stopped right at the deadline
+		default:
+			u.timeout = true
+		}
+	}
+
+	switch err := u.owner.checkAndIgnore(u.stopErr); {
+	case err != nil:
+		u.status.set(StateFailed, err)
+	case u.timeout:
+		u.status.set(StateFailed, ErrShutdownTimeout)
+	default:
+		u.status.set(StateStopped, nil)
+	}
+
+	return ShutdownResult{
+		Service:   u.name,
+		Budget:    budget,
+		Duration:  time.Since(start),
+		Abandoned: u.timeout,
+	}
+}
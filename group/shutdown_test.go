@@ -0,0 +1,80 @@
+package group
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownTimeout(t *testing.T) {
+	t.Run("should abandon service that was not stopped in time", func(t *testing.T) {
+		var (
+			report  []ShutdownResult
+			release = make(chan struct{})
+		)
+
+		defer close(release)
+
+		grp := New(
+			WithShutdownTimeout(time.Second),
+			WithShutdownReporter(func(v []ShutdownResult) { report = v }))
+
+		grp.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown, Name("fast"))
+
+		grp.Add(func(ctx context.Context) error {
+			<-release // blocks until the end of the test
+
+			return nil
+		}, noopShutdown, Name("stuck"), ShutdownTimeout(defaultAwait))
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAwait)
+		defer cancel()
+
+		start := time.Now()
+		err := grp.Run(ctx)
+		require.Less(t, time.Since(start), time.Second)
+
+		var list Errors
+		require.ErrorAs(t, err, &list)
+		require.Len(t, list, 1)
+		require.Equal(t, "stuck", list[0].Service)
+		require.Equal(t, PhaseTimeout, list[0].Phase)
+		require.ErrorIs(t, err, ErrShutdownTimeout)
+
+		require.Len(t, report, 2)
+		require.Equal(t, "fast", report[0].Service)
+		require.Equal(t, time.Second, report[0].Budget)
+		require.False(t, report[0].Abandoned)
+
+		require.Equal(t, "stuck", report[1].Service)
+		require.Equal(t, defaultAwait, report[1].Budget)
+		require.GreaterOrEqual(t, report[1].Duration, defaultAwait)
+		require.True(t, report[1].Abandoned)
+	})
+
+	t.Run("every service should have its own budget", func(t *testing.T) {
+		grp := New(WithShutdownTimeout(defaultAwait * 5))
+
+		slow := func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(defaultAwait * 3)
+
+			return nil
+		}
+
+		// total shutdown time exceeds budget of each service, but every service stops in time
+		grp.Add(slow, noopShutdown, Name("first"))
+		grp.Add(slow, noopShutdown, Name("second"), After("first"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAwait)
+		defer cancel()
+
+		require.NoError(t, grp.Run(ctx))
+	})
+}
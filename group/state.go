@@ -0,0 +1,169 @@
+package group
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+type (
+	// State of the service lifecycle.
+	State string
+
+	// ServiceState describes current state of the service.
+	ServiceState struct {
+		Name  string
+		State State
+
+		// Restarts is the count of restarts of the service in supervisor mode.
+		Restarts int
+
+		// LastError is the last error returned by the service, including errors that lead to restart.
+		LastError error
+
+		CreatedAt time.Time
+		StartedAt time.Time
+		StoppedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// status tracks the state of the service, it is safe for concurrent use.
+	status struct {
+		mu    sync.RWMutex
+		state ServiceState
+	}
+
+	jsonState struct {
+		Name      string     `json:"name"`
+		State     State      `json:"state"`
+		Restarts  int        `json:"restarts"`
+		LastError string     `json:"last_error,omitempty"`
+		CreatedAt time.Time  `json:"created_at"`
+		StartedAt *time.Time `json:"started_at,omitempty"`
+		StoppedAt *time.Time `json:"stopped_at,omitempty"`
+		UpdatedAt time.Time  `json:"updated_at"`
+	}
+)
+
+const (
+	// StateCreated is used for services that were added but not started yet.
+	StateCreated State = "created"
+
+	// StateStarting is used for services that wait for their dependencies or readiness.
+	StateStarting State = "starting"
+
+	// StateRunning is used for services that are up.
+	StateRunning State = "running"
+
+	// StateStopping is used for services that are stopping.
+	StateStopping State = "stopping"
+
+	// StateStopped is used for services that were gracefully stopped.
+	StateStopped State = "stopped"
+
+	// StateFailed is used for services that returned an error, could not be stopped or were abandoned.
+	StateFailed State = "failed"
+)
+
+var _ json.Marshaler = ServiceState{}
+
+// MarshalJSON encodes the state of the service, the last error is encoded as string.
+func (s ServiceState) MarshalJSON() ([]byte, error) {
+	out := jsonState{
+		Name:      s.Name,
+		State:     s.State,
+		Restarts:  s.Restarts,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+
+	if s.LastError != nil {
+		out.LastError = s.LastError.Error()
+	}
+
+	if !s.StartedAt.IsZero() {
+		out.StartedAt = &s.StartedAt
+	}
+
+	if !s.StoppedAt.IsZero() {
+		out.StoppedAt = &s.StoppedAt
+	}
+
+	return json.Marshal(out)
+}
+
+// States returns current states of all services in order of registration.
+func (g *group) States() []ServiceState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	result := make([]ServiceState, 0, len(g.services))
+	for i := range g.services {
+		result = append(result, g.services[i].status.get())
+	}
+
+	return result
+}
+
+func newStatus(name string) *status {
+	now := time.Now()
+
+	return &status{state: ServiceState{
+		Name:      name,
+		State:     StateCreated,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+}
+
+func (s *status) get() ServiceState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state
+}
+
+// set moves the service to the passed state:
+// - failed state is final, service can't leave it.
+// - service can be running only after starting.
+// - stopped and failed states store the time when service was stopped.
+func (s *status) set(state State, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.state.State == StateFailed:
+		return
+	case state == StateRunning && s.state.State != StateStarting:
+		return
+	}
+
+	now := time.Now()
+
+	switch state {
+	case StateRunning:
+		s.state.StartedAt = now
+	case StateStopped, StateFailed:
+		s.state.StoppedAt = now
+	}
+
+	if err != nil {
+		s.state.LastError = err
+	}
+
+	s.state.State = state
+	s.state.UpdatedAt = now
+}
+
+// restarted counts restart of the service and stores the error that lead to restart.
+func (s *status) restarted(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Restarts++
+	s.state.UpdatedAt = time.Now()
+
+	if err != nil {
+		s.state.LastError = err
+	}
+}
@@ -0,0 +1,136 @@
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStates(t *testing.T) {
+	t.Run("should track states of services", func(t *testing.T) {
+		grp := New(WithRestart(RestartConfig{
+			Policy:      RestartOnFailure,
+			MinBackoff:  time.Millisecond,
+			MaxBackoff:  time.Millisecond,
+			MaxRestarts: 100,
+		}))
+
+		attempts := 0
+		grp.Add(func(ctx context.Context) error {
+			if attempts++; attempts < 3 {
+				return errAlways
+			}
+
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown, Name("worker"))
+
+		grp.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown, Name("waiter"), After("worker"), WaitReady())
+
+		states := grp.States()
+		require.Len(t, states, 2)
+		require.Equal(t, StateCreated, states[0].State)
+		require.Equal(t, StateCreated, states[1].State)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() { done <- grp.Run(ctx) }()
+
+		require.Eventually(t, func() bool {
+			states = grp.States()
+
+			return states[0].Restarts == 2 && states[1].State == StateStarting
+		}, time.Second, time.Millisecond)
+
+		require.Equal(t, "worker", states[0].Name)
+		require.Equal(t, StateRunning, states[0].State)
+		require.ErrorIs(t, states[0].LastError, errAlways)
+		require.False(t, states[0].StartedAt.IsZero())
+
+		cancel()
+		require.NoError(t, <-done)
+
+		for _, state := range grp.States() {
+			require.Equal(t, StateStopped, state.State)
+			require.False(t, state.StoppedAt.IsZero())
+		}
+	})
+
+	t.Run("should mark failed services", func(t *testing.T) {
+		grp := New()
+		grp.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return nil
+		}, func(context.Context) error { return errAlways }, Name("not-stopped"))
+		grp.Add(func(context.Context) error { return errAlways }, noopShutdown, Name("failed"), After("not-stopped"))
+
+		require.ErrorIs(t, grp.Run(context.Background()), errAlways)
+
+		states := grp.States()
+		require.Equal(t, StateFailed, states[0].State)
+		require.ErrorIs(t, states[0].LastError, errAlways)
+		require.Equal(t, StateFailed, states[1].State)
+		require.ErrorIs(t, states[1].LastError, errAlways)
+	})
+
+	t.Run("should mark services that returned by themselves as stopped", func(t *testing.T) {
+		grp := New()
+		grp.Add(func(context.Context) error { return nil }, noopShutdown, Name("optional"), Optional())
+		grp.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return nil
+		}, noopShutdown, Name("worker"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() { done <- grp.Run(ctx) }()
+
+		require.Eventually(t, func() bool {
+			states := grp.States()
+
+			return states[0].State == StateStopped && states[1].State == StateRunning
+		}, time.Second, time.Millisecond)
+
+		require.False(t, grp.States()[0].StoppedAt.IsZero())
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("should encode state as json", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Second)
+		data, err := json.Marshal(ServiceState{
+			Name:      "worker",
+			State:     StateFailed,
+			Restarts:  1,
+			LastError: errAlways,
+			CreatedAt: now,
+			UpdatedAt: now,
+			StoppedAt: now,
+		})
+		require.NoError(t, err)
+
+		stamp := now.Format(time.RFC3339)
+		require.JSONEq(t, `{
+			"name": "worker",
+			"state": "failed",
+			"restarts": 1,
+			"last_error": "always",
+			"created_at": "`+stamp+`",
+			"stopped_at": "`+stamp+`",
+			"updated_at": "`+stamp+`"
+		}`, string(data))
+	})
+}
@@ -2,17 +2,19 @@ package helium
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	stdlog "log"
+	"io"
 	"os"
-	"reflect"
 	"strings"
 
+	"github.com/spf13/pflag"
 	"go.uber.org/atomic"
 	"go.uber.org/dig"
 	"go.uber.org/zap"
 
-	"github.com/im-kulikov/helium/logger"
+	"github.com/im-kulikov/helium/cli"
+	"github.com/im-kulikov/helium/group"
 	"github.com/im-kulikov/helium/module"
 	"github.com/im-kulikov/helium/settings"
 )
@@ -23,20 +25,45 @@ type (
 		Run(ctx context.Context) error
 	}
 
+	// runParams contains lifecycle hooks and the logger of the application, that is synced by Exit.
+	runParams struct {
+		dig.In
+
+		Hooks  []Hook      `group:"lifecycle_hooks"`
+		Logger *zap.Logger `optional:"true"`
+	}
+
 	// Helium struct.
 	Helium struct {
-		di *dig.Container
+		di      *dig.Container
+		modules module.Module
+		tracker *module.Tracker
 	}
 
 	// Settings struct.
 	Settings struct {
-		File         string
-		Type         string
+		File string
+		Type string
+
+		// Files contains additional config files and directories, Env selects overlays of config files,
+		// see settings.Core.ConfigFiles.
+		Files []string
+		Env   string
+
 		Name         string
 		Prefix       string
 		BuildTime    string
 		BuildVersion string
 		Defaults     settings.Defaults
+
+		// Commands allows to add custom commands, see Execute.
+		Commands []*cli.Command
+
+		// Output of commands, os.Stdout is used by default.
+		Output io.Writer
+
+		// flags of the command are bound into settings, see Execute.
+		flags *pflag.FlagSet
 	}
 )
 
@@ -46,13 +73,24 @@ var (
 
 	// nolint:gochecknoglobals
 	appVersion = atomic.NewString("dev")
+
+	// appTracker is used to find constructors of DI errors, see Diagnose.
+	// nolint:gochecknoglobals
+	appTracker = atomic.NewPointer[module.Tracker](nil)
+
+	// appLogger is the logger of the running application, it is used by Exit.
+	// nolint:gochecknoglobals
+	appLogger = atomic.NewPointer[zap.Logger](nil)
 )
 
 // New helium instance.
 func New(cfg *Settings, mod ...module.Module) (*Helium, error) {
-	h := &Helium{di: dig.New()}
+	h := &Helium{di: dig.New(), tracker: module.NewTracker()}
+	appTracker.Store(h.tracker)
+	appLogger.Store(nil)
 
 	modules := module.Combine(mod...)
+	modules = append(modules, &module.Provider{Constructor: h.Tracker})
 
 	if cfg != nil {
 		if cfg.Prefix == "" {
@@ -69,31 +107,58 @@ func New(cfg *Settings, mod ...module.Module) (*Helium, error) {
 			cfg.Type = tmp
 		}
 
+		if tmp := os.Getenv(cfg.Prefix + "_CONFIG_FILES"); tmp != "" {
+			cfg.Files = append(cfg.Files, strings.Split(tmp, ",")...)
+		}
+
+		if tmp := os.Getenv(cfg.Prefix + "_ENV"); tmp != "" {
+			cfg.Env = tmp
+		}
+
 		core := settings.Core{
 			File:         cfg.File,
 			Type:         cfg.Type,
+			Files:        cfg.Files,
+			Env:          cfg.Env,
 			Name:         cfg.Name,
 			Prefix:       cfg.Prefix,
 			BuildTime:    cfg.BuildTime,
 			BuildVersion: cfg.BuildVersion,
+			Flags:        cfg.flags,
 		}
 
+		// version and build time are taken from debug.ReadBuildInfo, when they are not injected by ldflags
+		build := settings.NewBuildInfo(&core)
+		core.BuildVersion, core.BuildTime = build.Version, build.Time
+
 		appName.Store(cfg.Name)
-		appVersion.Store(cfg.BuildVersion)
+		appVersion.Store(build.Version)
 
-		modules = append(modules, core.Provider())
+		modules = append(modules, core.Provider(), build.Provider())
 		modules = append(modules, settings.DIProvider(h.di))
+
+		if cfg.Defaults != nil {
+			// defaults could enable conditional modules, so they are set before conditions are checked
+			modules = append(modules, module.Setup(cfg.Defaults)...)
+		}
 	}
 
-	if err := module.Provide(h.di, modules); err != nil {
+	if err := h.tracker.Provide(h.di, modules); err != nil {
 		return nil, err
 	}
 
-	if cfg == nil || cfg.Defaults == nil {
-		return h, nil
+	// conditions and overrides are resolved here to validate only used constructors, see Validate
+	modules, err := module.Enable(h.di, modules)
+	if err != nil {
+		return nil, err
 	}
 
-	return h, h.di.Invoke(cfg.Defaults)
+	if h.modules, err = modules.Resolve(); err != nil {
+		return nil, err
+	}
+
+	// invoke functions of modules are called when settings defaults are set
+	return h, h.modules.Invoke(h.di)
 }
 
 // Invoke dependencies from DI container.
@@ -101,66 +166,66 @@ func (h Helium) Invoke(fn interface{}, args ...dig.InvokeOption) error {
 	return h.di.Invoke(fn, args...)
 }
 
+// Tracker returns tracker of constructors, it is provided into DI container.
+func (h Helium) Tracker() *module.Tracker {
+	return h.tracker
+}
+
+// Graph returns the dependency graph of the DI container,
+// every node is annotated with its module and whether it was constructed.
+func (h Helium) Graph() *module.Graph {
+	return h.tracker.Graph()
+}
+
 // Run trying invoke app instance from DI container and start app with Run call.
+// Lifecycle hooks (see Hook) are called around the app.
 func (h Helium) Run() error {
-	return h.di.Invoke(func(ctx context.Context, app App) error {
-		return app.Run(ctx)
-	})
+	return h.di.Invoke(runApp)
 }
 
-// Catch errors.
+// runApp runs the application with lifecycle hooks,
+// the logger of the application is stored to log and flush errors of the application by Exit.
+func runApp(ctx context.Context, app App, p runParams) error {
+	if p.Logger != nil {
+		appLogger.Store(p.Logger)
+	}
+
+	return newHooks(p.Hooks).run(ctx, app)
+}
+
+// Catch errors, the diagnostic report of the error (see Diagnose) is logged
+// and the process exits with the code of the error (see Exit and ExitCode).
 func Catch(err error) {
 	if err == nil {
 		return
 	}
 
-	log, logErr := logger.
-		NewLogger(logger.NewLoggerConfig(settings.Viper()), &settings.Core{
-			Name:         appName.Load(),
-			BuildVersion: appVersion.Load(),
-		})
-	if logErr != nil {
-		stdlog.Fatal(err)
-	} else {
-		log.Fatal("Can't run app",
-			zap.Error(err))
+	Exit(err)
+}
+
+// logServicesErrors logs every error of the services group separately.
+func logServicesErrors(log *zap.Logger, err error) {
+	var errs group.Errors
+	if !errors.As(err, &errs) {
+		return
+	}
+
+	for _, item := range errs {
+		log.Error("service failed",
+			zap.String("service", item.Service),
+			zap.String("phase", string(item.Phase)),
+			zap.Error(item.Err))
 	}
 }
 
-// CatchTrace catch errors for debugging use that function just for debug your application.
+// CatchTrace prints the diagnostic report of the error (see Diagnose) and panics,
+// use that function just for debug your application.
 func CatchTrace(err error) {
 	if err == nil {
 		return
 	}
 
-	// digging into the root of the problem
-loop:
-	for {
-		var (
-			ok bool
-			v  = reflect.ValueOf(err)
-			fn reflect.Value
-		)
-
-		switch {
-		case v.Type().Kind() != reflect.Struct,
-			!v.FieldByName("Reason").IsValid():
-			break loop
-		case v.FieldByName("Func").IsValid():
-			fn = v.FieldByName("Func")
-		}
-
-		// nolint:forbidigo
-		fmt.Printf("Place: %#v\nReason: %s\n\n", fn, err)
-
-		if err, ok = v.FieldByName("Reason").Interface().(error); ok {
-			continue
-		}
-
-		if err, ok = v.Interface().(error); ok {
-			break
-		}
-	}
+	fmt.Fprintln(os.Stderr, Diagnose(err))
 
 	panic(err)
 }
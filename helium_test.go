@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
@@ -14,8 +15,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/dig"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/im-kulikov/helium/grace"
+	"github.com/im-kulikov/helium/group"
 	"github.com/im-kulikov/helium/internal"
 	"github.com/im-kulikov/helium/logger"
 	"github.com/im-kulikov/helium/module"
@@ -91,6 +94,27 @@ func TestHelium(t *testing.T) {
 		require.NoError(t, h.Run())
 	})
 
+	t.Run("create new helium with layered config", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yml"), []byte("port: 80\nname: base\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.prod.yml"), []byte("port: 8080\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "secrets.json"), []byte(`{"token": "secret"}`), 0o600))
+
+		t.Setenv("LAYERS_ENV", "prod")
+		t.Setenv("LAYERS_CONFIG_FILES", filepath.Join(dir, "secrets.json"))
+
+		h, err := New(&Settings{Name: "layers", File: filepath.Join(dir, "config.yml")}, settings.Module)
+		require.NoError(t, err)
+
+		require.NoError(t, h.Invoke(func(v *viper.Viper, sources *settings.Sources) {
+			require.Equal(t, "base", v.GetString("name"))
+			require.Equal(t, 8080, v.GetInt("port"))
+			require.Equal(t, "secret", v.GetString("token"))
+			require.Equal(t, filepath.Join(dir, "config.prod.yml"), sources.Source("port"))
+			require.Equal(t, filepath.Join(dir, "secrets.json"), sources.Source("token"))
+		}))
+	})
+
 	t.Run("start new helium default app with json logger and no_caller should not cause exceptions", func(t *testing.T) {
 		require.NotPanics(t, func() {
 			h, err := New(&Settings{
@@ -161,8 +185,71 @@ func TestHelium(t *testing.T) {
 		})
 	})
 
+	t.Run("should provide build info", func(t *testing.T) {
+		h, err := New(&Settings{Name: "test-app", BuildVersion: "v1.0.0", BuildTime: "now"})
+		require.NoError(t, err)
+
+		require.NoError(t, h.Invoke(func(info *settings.BuildInfo, core *settings.Core) {
+			require.Equal(t, "test-app", info.Name)
+			require.Equal(t, "v1.0.0", info.Version)
+			require.Equal(t, "now", info.Time)
+			require.Equal(t, info.Version, core.BuildVersion)
+		}))
+
+		h, err = New(&Settings{Name: "test-app"})
+		require.NoError(t, err)
+
+		require.NoError(t, h.Invoke(func(info *settings.BuildInfo, core *settings.Core) {
+			require.NotEmpty(t, info.Version)
+			require.Equal(t, info.Version, core.BuildVersion)
+		}))
+	})
+
+	t.Run("should honor overrides and decorators", func(t *testing.T) {
+		core, logs := observer.New(zap.InfoLevel)
+
+		h, err := New(&Settings{}, grace.Module, settings.Module, logger.Module,
+			module.New(func() App { return heliumApp{} }),
+			module.Override(func() *zap.Logger { return zap.New(core) }),
+			module.Decorate(func(l *zap.Logger) *zap.Logger { return l.Named("decorated") }))
+		require.NoError(t, err)
+		require.NoError(t, h.Validate())
+
+		require.NoError(t, h.Invoke(func(l *zap.Logger) { l.Info("test") }))
+		require.Equal(t, "decorated", logs.TakeAll()[0].LoggerName)
+	})
+
+	t.Run("defaults should enable conditional modules", func(t *testing.T) {
+		h, err := New(&Settings{Defaults: func(v *viper.Viper) {
+			v.SetDefault("feature.enabled", true)
+			v.SetDefault(settings.WatchKey, true)
+		}},
+			settings.Module,
+			logger.Module,
+			module.When("feature.enabled", module.New(func() *heliumApp { return &heliumApp{} })))
+		require.NoError(t, err)
+
+		require.NoError(t, h.Invoke(func(*heliumApp, *settings.Reloader) {}))
+	})
+
+	t.Run("should call invoke functions of modules", func(t *testing.T) {
+		var routes []string
+
+		h, err := New(&Settings{Defaults: func(v *viper.Viper) { v.SetDefault("routes.prefix", "/api") }},
+			settings.Module,
+			module.Invoke(func(v *viper.Viper) { routes = append(routes, v.GetString("routes.prefix")+"/users") }),
+			module.Named(module.Info{Name: "routes"}, module.Invoke(func(*viper.Viper) error { return errTest })))
+		require.NotNil(t, h)
+		require.Equal(t, []string{"/api/users"}, routes)
+
+		var invokeErr *module.InvokeError
+		require.ErrorAs(t, err, &invokeErr)
+		require.ErrorIs(t, err, errTest)
+		require.Equal(t, "routes", invokeErr.Module.Name)
+	})
+
 	t.Run("check catch", func(t *testing.T) {
-		t.Run("should panic", func(t *testing.T) {
+		t.Run("should exit with mapped code without logger", func(t *testing.T) {
 			var exitCode int
 
 			monkey.Patch(os.Exit, func(code int) { exitCode = code })
@@ -174,9 +261,9 @@ func TestHelium(t *testing.T) {
 				return nil, errTest
 			})
 			defer monkey.Unpatch(logger.NewLogger)
-			err := errTest
+			err := fmt.Errorf("%w: test", settings.ErrConfig)
 			Catch(err)
-			require.Equal(t, 2, exitCode)
+			require.Equal(t, ExitConfig, exitCode)
 		})
 
 		t.Run("should catch error", func(t *testing.T) {
@@ -202,6 +289,37 @@ func TestHelium(t *testing.T) {
 			require.Equal(t, 1, exitCode)
 		})
 
+		t.Run("should log services errors", func(t *testing.T) {
+			var exitCode int
+
+			monkey.Patch(os.Exit, func(code int) { exitCode = code })
+			monkey.Patch(log.Fatal, func(...interface{}) { exitCode = 2 })
+
+			defer monkey.UnpatchAll()
+
+			core, logs := observer.New(zap.ErrorLevel)
+			monkey.Patch(logger.NewLogger, func(*logger.Config, *settings.Core) (*zap.Logger, error) {
+				return zap.New(core), nil
+			})
+			defer monkey.Unpatch(logger.NewLogger)
+
+			Catch(group.Errors{
+				{Service: "first", Phase: group.PhaseStart, Err: errTest},
+				{Service: "second", Phase: group.PhaseTimeout, Err: group.ErrShutdownTimeout},
+			})
+			require.Equal(t, 1, exitCode)
+
+			entries := logs.FilterMessage("service failed").All()
+			require.Len(t, entries, 2)
+			require.Equal(t, map[string]interface{}{
+				"service": "first",
+				"phase":   "start",
+				"error":   errTest.Error(),
+			}, entries[0].ContextMap())
+			require.Equal(t, "second", entries[1].ContextMap()["service"])
+			require.Equal(t, "timeout", entries[1].ContextMap()["phase"])
+		})
+
 		t.Run("shouldn't catch any", func(t *testing.T) {
 			var exitCode int
 
@@ -295,6 +413,9 @@ func TestHelium(t *testing.T) {
 			monkey.Patch(os.Exit, func(code int) { exitCode = code })
 			monkey.Patch(log.Fatal, func(...interface{}) { exitCode = 2 })
 
+			// patched os.Exit would hide failed tests, because the test binary could not exit with error
+			defer monkey.UnpatchAll()
+
 			require.Panics(t, func() {
 				CatchTrace(TestError{
 					Index: 1,
@@ -0,0 +1,254 @@
+package helium
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/service"
+)
+
+type (
+	// Hook allows modules to run setup and teardown code on stages of the application lifecycle.
+	// Hooks should be provided into DI by `group:"lifecycle_hooks"`.
+	// - start hooks are called in ascending order (by Order and then by Name).
+	// - stop hooks are called in reverse order.
+	// - every hook function receives context with timeout (see Timeout and DefaultHookTimeout).
+	Hook struct {
+		Name  string
+		Order int
+
+		// Timeout of every hook function, DefaultHookTimeout is used when it is not set.
+		Timeout time.Duration
+
+		// OnStart is called before the application starts, failure aborts startup
+		// and stops hooks that were started before (OnStop and OnStopped are called in reverse order).
+		OnStart HookFunc
+
+		// OnStarted is called when all services are up (see service.Readiness),
+		// failure stops the application.
+		OnStarted HookFunc
+
+		// OnStop is called before the application stops.
+		OnStop HookFunc
+
+		// OnStopped is called after the application was stopped.
+		OnStopped HookFunc
+	}
+
+	// HookFunc is a function of lifecycle hook.
+	HookFunc func(context.Context) error
+
+	// HookStage is a stage of the application lifecycle.
+	HookStage string
+
+	// HookError contains the name of the failed hook and the stage where error has occurred.
+	HookError struct {
+		Hook  string
+		Stage HookStage
+		Err   error
+	}
+
+	hooks []Hook
+)
+
+const (
+	// StageStart is used for hooks that are called before the application starts.
+	StageStart HookStage = "start"
+
+	// StageStarted is used for hooks that are called when all services are up.
+	StageStarted HookStage = "started"
+
+	// StageStop is used for hooks that are called before the application stops.
+	StageStop HookStage = "stop"
+
+	// StageStopped is used for hooks that are called after the application was stopped.
+	StageStopped HookStage = "stopped"
+
+	// DefaultHookTimeout is used when timeout of the hook is not set.
+	DefaultHookTimeout = time.Second * 15
+)
+
+var _ error = (*HookError)(nil)
+
+// Error returns error message as string.
+func (e *HookError) Error() string {
+	return fmt.Sprintf("hook %q %s: %v", e.Hook, e.Stage, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *HookError) Unwrap() error { return e.Err }
+
+func (h Hook) stage(stage HookStage) HookFunc {
+	switch stage {
+	case StageStart:
+		return h.OnStart
+	case StageStarted:
+		return h.OnStarted
+	case StageStop:
+		return h.OnStop
+	case StageStopped:
+		return h.OnStopped
+	default:
+		return nil
+	}
+}
+
+// newHooks sorts hooks, because DI passes them in random order.
+func newHooks(list []Hook) hooks {
+	result := append(hooks(nil), list...)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Order != result[j].Order {
+			return result[i].Order < result[j].Order
+		}
+
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// run calls lifecycle hooks around the application:
+// - when start hook fails, application is not started and hooks that were started before are stopped.
+// - when started hook fails, application is stopped and the error is returned.
+// - stop hooks are called before the application context is canceled.
+// - all stop hooks are called, application error takes precedence over hooks errors.
+func (h hooks) run(ctx context.Context, app App) error {
+	if err := h.start(ctx); err != nil {
+		return err
+	}
+
+	// application context should be canceled only after stop hooks
+	appCtx, cancel := context.WithCancel(internal.Detached{Context: ctx})
+	defer cancel()
+
+	exit := make(chan error, 1)
+
+	go func() { exit <- app.Run(appCtx) }()
+
+	running, err := h.started(ctx, app, exit)
+
+	stopCtx := internal.Detached{Context: ctx}
+	stopErr := h.call(stopCtx, StageStop)
+
+	cancel()
+
+	if running {
+		if appErr := <-exit; err == nil {
+			err = appErr
+		}
+	}
+
+	if hookErr := h.call(stopCtx, StageStopped); stopErr == nil {
+		stopErr = hookErr
+	}
+
+	if err == nil {
+		err = stopErr
+	}
+
+	return err
+}
+
+// started waits until application is up and calls started hooks,
+// then it waits until context is done or application returns.
+// It returns whether application is still running and an error.
+func (h hooks) started(ctx context.Context, app App, exit <-chan error) (bool, error) {
+	if ready, ok := app.(service.Readiness); ok {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case err := <-exit:
+			return false, err
+		case <-ready.Ready():
+		}
+	}
+
+	if err := h.call(ctx, StageStarted); err != nil {
+		return true, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return true, nil
+	case err := <-exit:
+		return false, err
+	}
+}
+
+// start calls start hooks in order, the first error aborts the stage.
+// Stop and stopped hooks of the hooks, that were started before the failed one, are called in reverse order,
+// their errors are ignored, because the error of start hook takes precedence.
+func (h hooks) start(ctx context.Context) error {
+	for i := range h {
+		err := h[i].call(ctx, StageStart)
+		if err == nil {
+			continue
+		}
+
+		stopCtx := internal.Detached{Context: ctx}
+		_ = h[:i].call(stopCtx, StageStop)
+		_ = h[:i].call(stopCtx, StageStopped)
+
+		return err
+	}
+
+	return nil
+}
+
+// call calls hooks of the stage (see start for start hooks), every hook receives context with timeout.
+// Started hooks are called in order and the first error aborts the stage.
+// Stop hooks are called in reverse order, all of them are called and the first error is returned.
+func (h hooks) call(ctx context.Context, stage HookStage) error {
+	var (
+		result  error
+		reverse = stage == StageStop || stage == StageStopped
+	)
+
+	for i := range h {
+		hook := h[i]
+		if reverse {
+			hook = h[len(h)-1-i]
+		}
+
+		err := hook.call(ctx, stage)
+		if err == nil {
+			continue
+		}
+
+		if !reverse {
+			return err
+		}
+
+		if result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+// call calls the hook function of the stage with timeout.
+func (h Hook) call(ctx context.Context, stage HookStage) error {
+	fn := h.stage(stage)
+	if fn == nil {
+		return nil
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := fn(tctx); err != nil {
+		return &HookError{Hook: h.Name, Stage: stage, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package helium
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/module"
+)
+
+type (
+	hooksOut struct {
+		dig.Out
+
+		Hooks []Hook `group:"lifecycle_hooks,flatten"`
+	}
+
+	readyApp struct {
+		ready chan struct{}
+		runs  func(context.Context) error
+	}
+
+	recorder struct {
+		sync.Mutex
+
+		events []string
+	}
+)
+
+func (a *readyApp) Run(ctx context.Context) error {
+	close(a.ready)
+
+	return a.runs(ctx)
+}
+
+func (a *readyApp) Ready() <-chan struct{} { return a.ready }
+
+// hook records calls of the hook, it fails on passed stage.
+func (r *recorder) hook(name string, order int, fail HookStage) Hook {
+	record := func(stage HookStage) HookFunc {
+		return func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return context.DeadlineExceeded // every hook should receive context with timeout
+			}
+
+			r.add(name + ":" + string(stage))
+
+			if stage == fail {
+				return errTest
+			}
+
+			return nil
+		}
+	}
+
+	return Hook{
+		Name:      name,
+		Order:     order,
+		OnStart:   record(StageStart),
+		OnStarted: record(StageStarted),
+		OnStop:    record(StageStop),
+		OnStopped: record(StageStopped),
+	}
+}
+
+func (r *recorder) add(event string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) list() []string {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]string(nil), r.events...)
+}
+
+func TestHooks(t *testing.T) {
+	prepare := func(t *testing.T, app App, list ...Hook) *Helium {
+		h, err := New(nil, module.Module{
+			{Constructor: func() context.Context { return context.Background() }},
+			{Constructor: func() App { return app }},
+			{Constructor: func() hooksOut { return hooksOut{Hooks: list} }},
+		})
+		require.NoError(t, err)
+
+		return h
+	}
+
+	t.Run("should call hooks in order", func(t *testing.T) {
+		rec := new(recorder)
+
+		// app does not implement Readiness, started hooks are called right after app starts
+		h := prepare(t, heliumApp{},
+			rec.hook("second", 1, ""),
+			rec.hook("first", 0, ""),
+			rec.hook("third", 1, ""))
+
+		require.NoError(t, h.Run())
+		require.Equal(t, []string{
+			"first:start", "second:start", "third:start",
+			"first:started", "second:started", "third:started",
+			"third:stop", "second:stop", "first:stop",
+			"third:stopped", "second:stopped", "first:stopped",
+		}, rec.list())
+	})
+
+	t.Run("should call stop hooks before app is stopped", func(t *testing.T) {
+		rec := new(recorder)
+		started := make(chan struct{})
+		app := &readyApp{ready: make(chan struct{}), runs: func(ctx context.Context) error {
+			<-ctx.Done()
+			rec.add("app")
+
+			return nil
+		}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		h, err := New(nil, module.Module{
+			{Constructor: func() context.Context { return ctx }},
+			{Constructor: func() App { return app }},
+			{Constructor: func() hooksOut {
+				return hooksOut{Hooks: []Hook{
+					rec.hook("hook", 0, ""),
+					{Name: "notify", Order: 1, OnStarted: func(context.Context) error {
+						close(started)
+
+						return nil
+					}},
+				}}
+			}},
+		})
+		require.NoError(t, err)
+
+		go func() {
+			<-started
+			cancel()
+		}()
+
+		require.NoError(t, h.Run())
+		require.Equal(t, []string{"hook:start", "hook:started", "hook:stop", "app", "hook:stopped"}, rec.list())
+	})
+
+	t.Run("should abort startup when start hook fails", func(t *testing.T) {
+		rec := new(recorder)
+		app := &readyApp{ready: make(chan struct{}), runs: func(context.Context) error {
+			rec.add("app")
+
+			return nil
+		}}
+
+		h := prepare(t, app,
+			rec.hook("first", 0, ""),
+			rec.hook("second", 0, ""),
+			rec.hook("failed", 1, StageStart),
+			rec.hook("skipped", 2, ""))
+
+		err := h.Run()
+		require.ErrorIs(t, err, errTest)
+		require.EqualError(t, err, `hook "failed" start: `+errTest.Error())
+
+		var hookErr *HookError
+		require.ErrorAs(t, err, &hookErr)
+		require.Equal(t, StageStart, hookErr.Stage)
+		// hooks that were started before the failed one are stopped in reverse order
+		require.Equal(t, []string{
+			"first:start", "second:start", "failed:start",
+			"second:stop", "first:stop",
+			"second:stopped", "first:stopped",
+		}, rec.list())
+	})
+
+	t.Run("should stop app when started hook fails", func(t *testing.T) {
+		rec := new(recorder)
+		app := &readyApp{ready: make(chan struct{}), runs: func(ctx context.Context) error {
+			<-ctx.Done()
+			rec.add("app")
+
+			return nil
+		}}
+
+		h := prepare(t, app, rec.hook("failed", 0, StageStarted))
+
+		done := make(chan error)
+
+		go func() { done <- h.Run() }()
+
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, errTest)
+		case <-time.After(time.Second):
+			t.Fatal("app should be stopped")
+		}
+
+		require.Equal(t, []string{
+			"failed:start", "failed:started", "failed:stop", "app", "failed:stopped",
+		}, rec.list())
+	})
+
+	t.Run("should return app error", func(t *testing.T) {
+		h := prepare(t, heliumErrApp{}, Hook{Name: "stop", OnStop: func(context.Context) error { return context.Canceled }})
+
+		require.ErrorIs(t, h.Run(), errTest)
+	})
+
+	t.Run("should return error of stop hook", func(t *testing.T) {
+		h := prepare(t, heliumApp{}, Hook{Name: "stop", OnStop: func(context.Context) error { return errTest }})
+
+		err := h.Run()
+		require.ErrorIs(t, err, errTest)
+		require.EqualError(t, err, `hook "stop" stop: `+errTest.Error())
+	})
+}
@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// Detached context keeps values of the parent context,
+// but it is never canceled by the parent context.
+type Detached struct{ context.Context }
+
+// Deadline returns no deadline.
+func (Detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// Done returns nil channel, so detached context is never done.
+func (Detached) Done() <-chan struct{} { return nil }
+
+// Err always returns nil.
+func (Detached) Err() error { return nil }
@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers the collector in the default registry without panics.
+// When the same collector is already registered (e.g. by the previous call), the existing one is returned,
+// when the name is taken by the application, the passed collector is returned unregistered.
+func Register[T prometheus.Collector](collector T) T {
+	err := prometheus.Register(collector)
+
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		// the same descriptor could be registered by collector of other type, e.g. gauge instead of counter
+		existing, ok := registered.ExistingCollector.(T)
+		if ok && reflect.TypeOf(existing) == reflect.TypeOf(collector) {
+			return existing
+		}
+	}
+
+	return collector
+}
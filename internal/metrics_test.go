@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	opts := prometheus.CounterOpts{Name: "internal_register_test_total", Help: "test"}
+
+	t.Run("should return existing collector", func(t *testing.T) {
+		first := Register(prometheus.NewCounter(opts))
+		second := Register(prometheus.NewCounter(opts))
+		require.Same(t, first, second)
+
+		require.True(t, prometheus.Unregister(first))
+	})
+
+	t.Run("should not panic when name is taken", func(t *testing.T) {
+		opts := prometheus.CounterOpts{Name: "internal_register_taken_total", Help: "test"}
+
+		taken := prometheus.NewGauge(prometheus.GaugeOpts{Name: opts.Name, Help: opts.Help})
+		require.NoError(t, prometheus.Register(taken))
+
+		defer prometheus.Unregister(taken)
+
+		counter := prometheus.NewCounter(opts)
+		require.NotPanics(t, func() { require.Same(t, counter, Register(counter)) })
+	})
+}
@@ -6,9 +6,10 @@ import (
 
 // Module of loggers
 // nolint:gochecknoglobals
-var Module = module.Module{
+var Module = module.Named(module.Info{Name: "logger.Module"}, module.Module{
 	{Constructor: NewLoggerConfig},
 	{Constructor: NewLogger},
 	{Constructor: NewStdLogger},
 	{Constructor: NewSugaredLogger},
-}
+	{Constructor: NewLevelSubscriber},
+})
@@ -21,9 +21,14 @@ type Config struct {
 	FullCaller   bool
 	NoDisclaimer bool
 	Sampling     *zap.SamplingConfig
+
+	// level is shared by loggers created from the config, it could be changed at runtime.
+	level *zap.AtomicLevel
 }
 
 const (
+	levelKey = "logger.level"
+
 	defaultSamplingInitial    = 100
 	defaultSamplingThereafter = 100
 )
@@ -32,7 +37,7 @@ const (
 func NewLoggerConfig(v *viper.Viper) *Config {
 	cfg := &Config{
 		Debug:        v.GetBool("debug"),
-		Level:        v.GetString("logger.level"),
+		Level:        v.GetString(levelKey),
 		TraceLevel:   v.GetString("logger.trace_level"),
 		Format:       v.GetString("logger.format"),
 		Color:        v.GetBool("logger.color"),
@@ -79,6 +84,25 @@ func SafeLevel(lvl string, defaultLvl zapcore.Level) zap.AtomicLevel {
 	}
 }
 
+// AtomicLevel returns level of loggers created from the config, changes of the level are applied to them.
+func (c *Config) AtomicLevel() zap.AtomicLevel {
+	if c.level == nil {
+		level := SafeLevel(c.Level, zapcore.InfoLevel)
+		c.level = &level
+	}
+
+	return *c.level
+}
+
+// NewLevelSubscriber applies new logger level, when config was reloaded (see settings.Reloader).
+func NewLevelSubscriber(cfg *Config) settings.SubscriberResult {
+	return settings.SubscriberResult{Subscriber: func(event settings.Event) {
+		if event.Changed(levelKey) {
+			cfg.AtomicLevel().SetLevel(SafeLevel(event.Settings.GetString(levelKey), zapcore.InfoLevel).Level())
+		}
+	}}
+}
+
 // SafeFormat returns valid logger output format use json by default.
 // nolint:goconst
 func (c Config) SafeFormat() string {
@@ -126,7 +150,7 @@ func NewLogger(lcfg *Config, app *settings.Core) (*zap.Logger, error) {
 		cfg.EncoderConfig.EncodeCaller = zapcore.FullCallerEncoder
 	}
 
-	cfg.Level = SafeLevel(lcfg.Level, zapcore.InfoLevel)
+	cfg.Level = lcfg.AtomicLevel()
 	traceLevel := SafeLevel(lcfg.TraceLevel, zapcore.WarnLevel)
 
 	l, err := cfg.Build(
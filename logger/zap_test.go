@@ -147,6 +147,25 @@ func TestZapLogger(t *testing.T) {
 			require.Nil(t, log)
 		})
 
+		t.Run("should change level when config was reloaded", func(t *testing.T) {
+			v := viper.New()
+			v.SetDefault("logger.level", "info")
+			cfg := NewLoggerConfig(v)
+			log, err := NewLogger(cfg, &settings.Core{})
+			require.NoError(t, err)
+			require.False(t, log.Core().Enabled(zapcore.DebugLevel))
+
+			subscriber := NewLevelSubscriber(cfg).Subscriber
+
+			v.Set("logger.format", "console")
+			subscriber(settings.Event{Settings: v, Changes: []settings.Change{{Key: "logger.format"}}})
+			require.False(t, log.Core().Enabled(zapcore.DebugLevel))
+
+			v.Set("logger.level", "debug")
+			subscriber(settings.Event{Settings: v, Changes: []settings.Change{{Key: "logger.level"}}})
+			require.True(t, log.Core().Enabled(zapcore.DebugLevel))
+		})
+
 		t.Run("check sugared", func(t *testing.T) {
 			v := viper.New()
 			v.SetDefault("logger.level", "info")
@@ -0,0 +1,195 @@
+package module
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
+)
+
+type (
+	// Condition decides whether the provider should be provided into DI container.
+	// Settings could be nil, when they are not provided.
+	Condition func(v *viper.Viper) bool
+
+	settingsParams struct {
+		dig.In
+
+		Viper *viper.Viper `optional:"true"`
+	}
+)
+
+// ProfilesKey is the key of settings, that contains active profiles (list or comma separated string).
+const ProfilesKey = "profiles"
+
+// If creates module, that is provided only when condition is true.
+// Nested conditions are combined, all of them should be true.
+func If(cond Condition, mods ...Module) Module {
+	var result Module
+
+	for _, p := range Combine(mods...) {
+		if p != nil {
+			clone := *p
+			clone.conditions = append(append([]Condition(nil), p.conditions...), cond)
+			p = &clone
+		}
+
+		result = append(result, p)
+	}
+
+	return result
+}
+
+// When creates module, that is provided only when the key of settings is true.
+func When(key string, mods ...Module) Module {
+	return If(func(v *viper.Viper) bool { return v != nil && v.GetBool(key) }, mods...)
+}
+
+// Unless creates module, that is provided unless the key of settings is true,
+// for example `module.Unless("api.disabled", mod)`.
+func Unless(key string, mods ...Module) Module {
+	return If(func(v *viper.Viper) bool { return v == nil || !v.GetBool(key) }, mods...)
+}
+
+// Profile creates module, that is provided only when the profile is active (see ProfilesKey).
+func Profile(name string, mods ...Module) Module {
+	return If(func(v *viper.Viper) bool {
+		for _, profile := range Profiles(v) {
+			if profile == name {
+				return true
+			}
+		}
+
+		return false
+	}, mods...)
+}
+
+// Profiles returns active profiles from settings.
+func Profiles(v *viper.Viper) []string {
+	if v == nil {
+		return nil
+	}
+
+	var result []string
+
+	for _, item := range v.GetStringSlice(ProfilesKey) {
+		for _, profile := range strings.Split(item, ",") {
+			if profile = strings.TrimSpace(profile); profile != "" {
+				result = append(result, profile)
+			}
+		}
+	}
+
+	return result
+}
+
+// Enabled returns providers, which conditions are true for passed settings.
+func (m Module) Enabled(v *viper.Viper) Module {
+	result := make(Module, 0, len(m))
+
+loop:
+	for _, p := range m {
+		if p == nil || len(p.conditions) == 0 {
+			result = append(result, p)
+
+			continue
+		}
+
+		for _, cond := range p.conditions {
+			if !cond(v) {
+				continue loop
+			}
+		}
+
+		clone := *p
+		clone.conditions = nil
+		result = append(result, &clone)
+	}
+
+	return result
+}
+
+// Enable returns providers, that are enabled by settings from DI container (see Module.Enabled),
+// conditions are checked with nil settings, when they are not provided into container.
+func Enable(dic *dig.Container, providers Module) (Module, error) {
+	if !providers.conditional() {
+		return providers, nil
+	}
+
+	var params settingsParams
+	if err := dic.Invoke(func(p settingsParams) { params = p }); err != nil {
+		return nil, err
+	}
+
+	return providers.Enabled(params.Viper), nil
+}
+
+// conditional returns true, when any provider has conditions.
+func (m Module) conditional() bool {
+	for _, p := range m {
+		if p != nil && len(p.conditions) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stage splits providers into unconditional ones, that could not be replaced by conditional providers or overrides,
+// and the rest of them, so the first ones could be provided before conditions are checked.
+// Decorators, invoke and setup functions are always in the rest.
+func (m Module) stage() (Module, Module) {
+	if !m.conditional() {
+		return nil, m
+	}
+
+	var (
+		late     = make(map[string]struct{})
+		deferred = make(map[*Provider]struct{})
+	)
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, p := range m {
+			if _, ok := deferred[p]; ok || p == nil {
+				continue
+			}
+
+			switch {
+			case p.kind == kindDecorator || p.kind == kindInvoke || p.kind == kindSetup:
+				deferred[p] = struct{}{}
+			case len(p.conditions) > 0 || p.overlaps(late):
+				// providers of the same types are resolved together, see Module.Resolve
+				for _, out := range outputs(p) {
+					late[out] = struct{}{}
+				}
+
+				deferred[p], changed = struct{}{}, true
+			}
+		}
+	}
+
+	var first, rest Module
+
+	for _, p := range m {
+		if _, ok := deferred[p]; ok || p == nil {
+			rest = append(rest, p)
+		} else {
+			first = append(first, p)
+		}
+	}
+
+	return first, rest
+}
+
+// overlaps returns true, when the provider provides any of passed types.
+func (p *Provider) overlaps(types map[string]struct{}) bool {
+	for _, out := range outputs(p) {
+		if _, ok := types[out]; ok {
+			return true
+		}
+	}
+
+	return false
+}
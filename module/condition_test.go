@@ -0,0 +1,122 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+)
+
+type conditionFeature string
+
+func TestConditions(t *testing.T) {
+	feature := func(name string) Module {
+		return New(func() conditionFeature { return conditionFeature(name) })
+	}
+
+	settings := func(values map[string]interface{}) *viper.Viper {
+		v := viper.New()
+		for key, val := range values {
+			v.Set(key, val)
+		}
+
+		return v
+	}
+
+	t.Run("should check conditions with settings", func(t *testing.T) {
+		mod := Combine(
+			When("feature.enabled", New(func() int { return 1 })),
+			Unless("feature.disabled", New(func() int8 { return 2 })),
+			When("feature.enabled", When("feature.beta", New(func() int16 { return 3 }))),
+			If(func(v *viper.Viper) bool { return v != nil && v.GetString("feature.name") == "x" }, New(func() int32 { return 4 })),
+			New(func() int64 { return 5 }))
+
+		require.Len(t, mod.Enabled(settings(nil)), 2)
+		require.Len(t, mod.Enabled(settings(map[string]interface{}{
+			"feature.enabled":  true,
+			"feature.disabled": true,
+			"feature.name":     "x",
+		})), 3)
+		require.Len(t, mod.Enabled(settings(map[string]interface{}{
+			"feature.enabled": true,
+			"feature.beta":    true,
+		})), 4)
+
+		require.Len(t, mod.Enabled(nil), 2, "without settings only unless conditions are true")
+	})
+
+	t.Run("should use active profiles", func(t *testing.T) {
+		mod := Combine(
+			feature("default"),
+			Profile("test", Override(func() conditionFeature { return "test" })),
+			Profile("dev", New(func() int { return 1 })))
+
+		require.Equal(t, []string{"dev", "test"}, Profiles(settings(map[string]interface{}{ProfilesKey: "dev, test"})))
+		require.Equal(t, []string{"dev", "test"}, Profiles(settings(map[string]interface{}{ProfilesKey: []string{"dev", "test"}})))
+		require.Empty(t, Profiles(nil))
+
+		dic := dig.New()
+		require.NoError(t, Provide(dic, Combine(
+			New(func() *viper.Viper { return settings(map[string]interface{}{ProfilesKey: "test"}) }),
+			mod)))
+
+		require.NoError(t, dic.Invoke(func(name conditionFeature) { require.Equal(t, conditionFeature("test"), name) }))
+		require.Error(t, dic.Invoke(func(int) {}))
+	})
+
+	t.Run("should take settings from container", func(t *testing.T) {
+		var calls int
+
+		dic := dig.New()
+		require.NoError(t, dic.Provide(func() *viper.Viper {
+			calls++
+
+			return settings(map[string]interface{}{"feature.enabled": true})
+		}))
+
+		mod, err := Enable(dic, When("feature.enabled", feature("enabled")))
+		require.NoError(t, err)
+		require.Len(t, mod, 1)
+		require.Equal(t, 1, calls)
+
+		require.NoError(t, Provide(dic, mod))
+		require.NoError(t, dic.Invoke(func(conditionFeature) {}))
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("should build settings once by the same container", func(t *testing.T) {
+		var calls int
+
+		dic := dig.New()
+		require.NoError(t, Provide(dic, Combine(
+			New(func() *viper.Viper {
+				calls++
+
+				return settings(map[string]interface{}{"feature.enabled": true})
+			}),
+			When("feature.enabled", feature("enabled")),
+			Unless("feature.enabled", New(func() int { return 1 })))))
+
+		require.NoError(t, dic.Invoke(func(name conditionFeature) { require.Equal(t, conditionFeature("enabled"), name) }))
+		require.Error(t, dic.Invoke(func(int) {}))
+		require.Equal(t, 1, calls)
+
+		err := Provide(dig.New(), Combine(
+			New(func() (*viper.Viper, error) { return nil, errTest }),
+			When("feature.enabled", feature("enabled"))))
+		require.ErrorIs(t, err, errTest)
+	})
+
+	t.Run("should check required modules of all providers", func(t *testing.T) {
+		mod := func(enabled bool) Module {
+			return Combine(
+				New(func() *viper.Viper { return settings(map[string]interface{}{"feature.enabled": enabled}) }),
+				Named(Info{Name: "first", Requires: []string{"second"}}, New(func() int { return 1 })),
+				When("feature.enabled", Named(Info{Name: "second"}, New(func() int8 { return 2 }))))
+		}
+
+		require.NoError(t, Provide(dig.New(), mod(true)))
+		require.ErrorIs(t, Provide(dig.New(), mod(false)), ErrRequiredModule)
+	})
+}
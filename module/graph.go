@@ -0,0 +1,278 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+type (
+	// Graph of the DI container, nodes are constructors and edges are types
+	// that are passed from one constructor to another.
+	Graph struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
+	}
+
+	// Node of the graph, it describes the constructor.
+	Node struct {
+		ID int `json:"id"`
+
+		// Name of the constructor function.
+		Name string `json:"name"`
+
+		// Package of the constructor function.
+		Package string `json:"package"`
+
+		// Module is the name of the module, that introduced the constructor (see Named),
+		// or the package of the constructor.
+		Module string `json:"module"`
+
+		// Location of the constructor (file:line).
+		Location string `json:"location"`
+
+		// Constructed is true when the constructor was called without error.
+		Constructed bool `json:"constructed"`
+
+		Inputs  []string `json:"inputs,omitempty"`
+		Outputs []string `json:"outputs,omitempty"`
+	}
+
+	// Edge of the graph, the type is provided by From node and required by To node.
+	Edge struct {
+		From int    `json:"from"`
+		To   int    `json:"to"`
+		Type string `json:"type"`
+	}
+
+	// Tracker provides constructors into DI container and tracks which of them were called.
+	Tracker struct {
+		mu    sync.RWMutex
+		nodes []*Node
+	}
+)
+
+const (
+	// ErrUnknownGraphFormat is raised when graph could not be written in passed format.
+	ErrUnknownGraphFormat = internal.Error("unknown graph format")
+
+	// GraphDOT is the format of Graphviz.
+	GraphDOT = "dot"
+
+	// GraphJSON is the JSON format.
+	GraphJSON = "json"
+)
+
+// NewTracker creates tracker of constructors.
+func NewTracker() *Tracker { return &Tracker{} }
+
+// Provide set providers functions to DI container, like Provide does,
+// but every constructor is wrapped to track its calls.
+func (t *Tracker) Provide(dic *dig.Container, providers Module) error {
+	return provide(dic, providers, func(p *Provider) error {
+		info := new(dig.ProvideInfo)
+		node := &Node{ID: len(t.nodes)}
+		constructor, options := t.wrap(node, p.Constructor)
+		if p.Module != nil {
+			node.Module = p.Module.String()
+		}
+
+		options = append(append(options, p.Options...), dig.FillProvideInfo(info))
+		if err := dic.Provide(constructor, options...); err != nil {
+			return err
+		}
+
+		for _, in := range info.Inputs {
+			node.Inputs = append(node.Inputs, in.String())
+		}
+
+		for _, out := range info.Outputs {
+			node.Outputs = append(node.Outputs, out.String())
+		}
+
+		t.mu.Lock()
+		t.nodes = append(t.nodes, node)
+		t.mu.Unlock()
+
+		return nil
+	})
+}
+
+// wrap returns constructor that marks the node as constructed,
+// location of the original constructor is passed to DI container for error messages.
+func (t *Tracker) wrap(node *Node, constructor interface{}) (interface{}, []dig.ProvideOption) {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return constructor, nil
+	}
+
+	pc := fn.Pointer()
+	if info := runtime.FuncForPC(pc); info != nil {
+		file, line := info.FileLine(pc)
+
+		node.Name, node.Package = splitName(info.Name())
+		node.Module = node.Package
+		node.Location = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	kind := fn.Type()
+	failed := kind.NumOut() > 0 && kind.Out(kind.NumOut()-1) == reflect.TypeOf((*error)(nil)).Elem()
+
+	wrapped := reflect.MakeFunc(kind, func(args []reflect.Value) []reflect.Value {
+		var out []reflect.Value
+		if kind.IsVariadic() {
+			out = fn.CallSlice(args)
+		} else {
+			out = fn.Call(args)
+		}
+
+		if !failed || out[len(out)-1].IsNil() {
+			t.mu.Lock()
+			node.Constructed = true
+			t.mu.Unlock()
+		}
+
+		return out
+	})
+
+	return wrapped.Interface(), []dig.ProvideOption{dig.LocationForPC(pc)}
+}
+
+// Graph returns the current graph of tracked constructors.
+func (t *Tracker) Graph() *Graph {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	graph := &Graph{Nodes: make([]Node, 0, len(t.nodes)), Edges: []Edge{}}
+	providers := make(map[string][]int)
+
+	for _, node := range t.nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+
+		for _, out := range node.Outputs {
+			providers[out] = append(providers[out], node.ID)
+		}
+	}
+
+	for _, node := range t.nodes {
+		for _, in := range node.Inputs {
+			key := dependencyKey(in)
+
+			for _, from := range providers[key] {
+				graph.Edges = append(graph.Edges, Edge{From: from, To: node.ID, Type: key})
+			}
+		}
+	}
+
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	return graph
+}
+
+// Write writes the graph in passed format (GraphDOT or GraphJSON).
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case GraphDOT:
+		return g.WriteDOT(w)
+	case GraphJSON:
+		return g.WriteJSON(w)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownGraphFormat, format)
+	}
+}
+
+// WriteJSON writes the graph as JSON.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(g)
+}
+
+// WriteDOT writes the graph in Graphviz format, constructors are grouped by modules,
+// constructed nodes are filled.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var (
+		out     strings.Builder
+		modules []string
+		nodes   = make(map[string][]Node)
+	)
+
+	for _, node := range g.Nodes {
+		if _, ok := nodes[node.Module]; !ok {
+			modules = append(modules, node.Module)
+		}
+
+		nodes[node.Module] = append(nodes[node.Module], node)
+	}
+
+	sort.Strings(modules)
+
+	out.WriteString("digraph {\n\trankdir=LR;\n\tnode [shape=box];\n")
+
+	for i, name := range modules {
+		fmt.Fprintf(&out, "\tsubgraph cluster_%d {\n\t\tlabel=%q;\n", i, name)
+
+		for _, node := range nodes[name] {
+			style := "dashed"
+			if node.Constructed {
+				style = "filled"
+			}
+
+			fmt.Fprintf(&out, "\t\t%d [label=%q, tooltip=%q, style=%s];\n",
+				node.ID, node.Name, node.Location, style)
+		}
+
+		out.WriteString("\t}\n")
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&out, "\t%d -> %d [label=%q];\n", edge.From, edge.To, edge.Type)
+	}
+
+	out.WriteString("}\n")
+
+	_, err := io.WriteString(w, out.String())
+
+	return err
+}
+
+// dependencyKey returns the key of the input, that is equal to the output of the constructor:
+// optional mark is removed and groups are required as slices of types.
+func dependencyKey(input string) string {
+	input = strings.Replace(input, "[optional]", "", 1)
+	input = strings.Replace(input, "optional, ", "", 1)
+
+	if strings.Contains(input, "group = ") {
+		input = strings.TrimPrefix(input, "[]")
+	}
+
+	return input
+}
+
+// splitName splits the full name of the function into name and package.
+func splitName(full string) (string, string) {
+	slash := strings.LastIndex(full, "/") + 1
+
+	dot := strings.Index(full[slash:], ".")
+	if dot < 0 {
+		return full, ""
+	}
+
+	return full[slash+dot+1:], full[:slash+dot]
+}
@@ -0,0 +1,98 @@
+package module
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+type (
+	graphConfig struct{}
+	graphServer struct{}
+
+	graphParams struct {
+		dig.In
+
+		Config  *graphConfig `optional:"true"`
+		Plugins []string     `group:"plugins"`
+	}
+)
+
+const errTest = internal.Error("test")
+
+func newGraphConfig() *graphConfig { return &graphConfig{} }
+
+func newGraphServer(graphParams) (*graphServer, error) { return &graphServer{}, nil }
+
+func newGraphPlugin(...int) string { return "plugin" }
+
+func TestTracker(t *testing.T) {
+	dic := dig.New()
+	tracker := NewTracker()
+
+	require.NoError(t, tracker.Provide(dic, Module{
+		{Constructor: newGraphConfig},
+		{Constructor: newGraphServer},
+		{Constructor: newGraphPlugin, Options: []dig.ProvideOption{dig.Group("plugins")}},
+		{Constructor: func(int) float64 { return 0 }},
+	}))
+
+	graph := tracker.Graph()
+	require.Len(t, graph.Nodes, 4)
+	require.Equal(t, "newGraphConfig", graph.Nodes[0].Name)
+	require.Equal(t, "github.com/im-kulikov/helium/module", graph.Nodes[0].Module)
+	require.Equal(t, "github.com/im-kulikov/helium/module", graph.Nodes[0].Package)
+	require.Contains(t, graph.Nodes[0].Location, "graph_test.go")
+	require.Equal(t, []string{"*module.graphConfig"}, graph.Nodes[0].Outputs)
+	require.Equal(t, []Edge{
+		{From: 0, To: 1, Type: "*module.graphConfig"},
+		{From: 2, To: 1, Type: `string[group = "plugins"]`},
+	}, graph.Edges)
+
+	for _, node := range graph.Nodes {
+		require.False(t, node.Constructed, node.Name)
+	}
+
+	require.NoError(t, dic.Invoke(func(*graphServer) {}))
+
+	graph = tracker.Graph()
+	require.True(t, graph.Nodes[0].Constructed)
+	require.True(t, graph.Nodes[1].Constructed)
+	require.True(t, graph.Nodes[2].Constructed, "variadic constructor")
+	require.False(t, graph.Nodes[3].Constructed)
+
+	t.Run("errors should point to original constructor", func(t *testing.T) {
+		err := dic.Invoke(func(float64) {})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "graph_test.go")
+	})
+
+	t.Run("failed constructor should not be marked", func(t *testing.T) {
+		failed, container := NewTracker(), dig.New()
+		require.NoError(t, failed.Provide(container, New(func() (int, error) { return 0, errTest })))
+		require.ErrorIs(t, container.Invoke(func(int) {}), errTest)
+		require.False(t, failed.Graph().Nodes[0].Constructed)
+	})
+
+	t.Run("should write graph", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, graph.Write(out, GraphDOT))
+		require.Contains(t, out.String(), `label="github.com/im-kulikov/helium/module"`)
+		require.Contains(t, out.String(), `0 [label="newGraphConfig"`)
+		require.Contains(t, out.String(), `0 -> 1 [label="*module.graphConfig"];`)
+
+		out.Reset()
+		require.NoError(t, graph.Write(out, GraphJSON))
+
+		var result Graph
+		require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+		require.Equal(t, *graph, result)
+
+		require.ErrorIs(t, graph.Write(out, "svg"), ErrUnknownGraphFormat)
+	})
+}
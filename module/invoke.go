@@ -0,0 +1,86 @@
+package module
+
+import (
+	"fmt"
+
+	"go.uber.org/dig"
+)
+
+// InvokeError is returned when invoke function of the module fails.
+type InvokeError struct {
+	// Invoke is the function and the module, that registered it.
+	Invoke string
+	Module *Info
+	Err    error
+}
+
+var _ error = (*InvokeError)(nil)
+
+// Invoke creates module with the function, that is called after all providers are registered (see Module.Invoke).
+// It allows to register side effects, such as gRPC services on *grpc.Server or routes.
+func Invoke(fn interface{}, opts ...dig.InvokeOption) Module {
+	return Module{{Constructor: fn, kind: kindInvoke, invoke: opts}}
+}
+
+// Setup creates module with the function, that is called when providers are passed into DI container,
+// but before conditions of modules are checked (see Enable), e.g. to set defaults of settings,
+// that enable conditional modules. When there are conditional modules, it could use only unconditional providers.
+// Conditions of setup functions are checked against settings before any of setup functions is called.
+func Setup(fn interface{}, opts ...dig.InvokeOption) Module {
+	return Module{{Constructor: fn, kind: kindSetup, invoke: opts}}
+}
+
+// Error returns the message with function and module, that failed.
+func (e *InvokeError) Error() string {
+	return fmt.Sprintf("invoke %s: %v", e.Invoke, e.Err)
+}
+
+// Unwrap returns the error of the function.
+func (e *InvokeError) Unwrap() error { return e.Err }
+
+// Invoke calls invoke functions of the module (see Invoke) in order of registration,
+// conditions are checked (see Enable). Provide doesn't call them, so they should be called after it.
+// Error is returned as *InvokeError.
+func (m Module) Invoke(dic *dig.Container) error {
+	providers, err := Enable(dic, m)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range providers {
+		if p == nil || p.kind != kindInvoke {
+			continue
+		}
+
+		if err = dic.Invoke(p.Constructor, p.invoke...); err != nil {
+			return &InvokeError{Invoke: Origin(p), Module: p.Module, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// setup calls setup functions of providers (see Setup) in order of registration.
+// Error is returned as *InvokeError.
+func setup(dic *dig.Container, providers Module) error {
+	var setups Module
+
+	for _, p := range providers {
+		if p != nil && p.kind == kindSetup {
+			setups = append(setups, p)
+		}
+	}
+
+	setups, err := Enable(dic, setups)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range setups {
+		if err = dic.Invoke(p.Constructor, p.invoke...); err != nil {
+			return &InvokeError{Invoke: Origin(p), Module: p.Module, Err: err}
+		}
+	}
+
+	return nil
+}
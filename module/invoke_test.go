@@ -0,0 +1,116 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+)
+
+type invokeRegistry struct{ names []string }
+
+func TestInvoke(t *testing.T) {
+	register := func(name string) Module {
+		return Invoke(func(r *invokeRegistry) { r.names = append(r.names, name) })
+	}
+
+	t.Run("should call invoke functions in order after all providers", func(t *testing.T) {
+		var (
+			dic      = dig.New()
+			registry = new(invokeRegistry)
+		)
+
+		mod := Combine(
+			register("first"),
+			Named(Info{Name: "routes"}, register("second")),
+			When("feature.enabled", register("disabled")),
+			New(func() *invokeRegistry { return registry }),
+			register("third"))
+
+		require.NoError(t, Provide(dic, mod))
+		require.Empty(t, registry.names, "should not be called by Provide")
+
+		require.NoError(t, mod.Invoke(dic))
+		require.Equal(t, []string{"first", "second", "third"}, registry.names)
+	})
+
+	t.Run("should not be treated as providers", func(t *testing.T) {
+		mod := Combine(
+			New(func() int { return 1 }),
+			Invoke(func() int { return 2 }),
+			Override(func() int { return 3 }))
+
+		resolved, err := mod.Resolve()
+		require.NoError(t, err)
+		require.Len(t, resolved, 2)
+		require.NoError(t, resolved.Check())
+	})
+
+	t.Run("should attribute errors to the module", func(t *testing.T) {
+		dic := dig.New()
+		mod := Combine(
+			New(func() *viper.Viper { return viper.New() }),
+			Named(Info{Name: "routes", Version: "v1.0.0"}, Invoke(func(*viper.Viper) error { return errTest })))
+		require.NoError(t, Provide(dic, mod))
+
+		err := mod.Invoke(dic)
+		require.ErrorIs(t, err, errTest)
+
+		var invokeErr *InvokeError
+		require.ErrorAs(t, err, &invokeErr)
+		require.Equal(t, "routes", invokeErr.Module.Name)
+		require.Contains(t, err.Error(), "(module routes@v1.0.0): test")
+
+		require.ErrorAs(t, Combine(register("missing")).Invoke(dig.New()), &invokeErr)
+		require.Nil(t, invokeErr.Module)
+	})
+}
+
+func TestSetup(t *testing.T) {
+	settings := func() Module { return New(func() *viper.Viper { return viper.New() }) }
+	enable := Setup(func(v *viper.Viper) { v.SetDefault("feature.enabled", true) })
+
+	t.Run("should be called before conditions are checked", func(t *testing.T) {
+		dic := dig.New()
+		require.NoError(t, Provide(dic, Combine(
+			When("feature.enabled", New(func() int { return 1 })),
+			Profile("local", Setup(func() { t.Fatal("profile is not active") })),
+			settings(),
+			enable)))
+
+		require.NoError(t, dic.Invoke(func(value int) { require.Equal(t, 1, value) }))
+	})
+
+	t.Run("should be called after all providers without conditions", func(t *testing.T) {
+		var calls int
+
+		dic := dig.New()
+		mod := Combine(
+			Setup(func(v *viper.Viper, value int) {
+				calls++
+				require.Equal(t, 1, value)
+			}),
+			settings(),
+			New(func() int { return 1 }))
+		require.NoError(t, Provide(dic, mod))
+		require.NoError(t, mod.Invoke(dic), "should not be called by Invoke")
+		require.Equal(t, 1, calls)
+
+		resolved, err := mod.Resolve()
+		require.NoError(t, err)
+		require.NoError(t, resolved.Check())
+	})
+
+	t.Run("should attribute errors to the module", func(t *testing.T) {
+		err := Provide(dig.New(), Combine(
+			settings(),
+			Named(Info{Name: "defaults"}, Setup(func(*viper.Viper) error { return errTest })),
+			When("feature.enabled", New(func() int { return 1 }))))
+		require.ErrorIs(t, err, errTest)
+
+		var invokeErr *InvokeError
+		require.ErrorAs(t, err, &invokeErr)
+		require.Equal(t, "defaults", invokeErr.Module.Name)
+	})
+}
@@ -1,7 +1,16 @@
 package module
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
 	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/internal"
 )
 
 type (
@@ -12,9 +21,73 @@ type (
 	Provider struct {
 		Constructor interface{}
 		Options     []dig.ProvideOption
+
+		// Module that introduced the provider, it is set by Named.
+		Module *Info
+
+		kind       kind
+		decorate   []dig.DecorateOption
+		invoke     []dig.InvokeOption
+		conditions []Condition
 	}
+
+	// Info describes the named module.
+	Info struct {
+		Name    string
+		Version string
+
+		// Requires contains names of modules, that should be combined with the module.
+		Requires []string
+	}
+
+	// Errors contains all problems found by Check.
+	Errors []error
 )
 
+const (
+	// ErrProviderConflict is raised when the same type is provided by multiple providers.
+	ErrProviderConflict = internal.Error("conflicting providers")
+
+	// ErrVersionConflict is raised when the module is combined with different versions.
+	ErrVersionConflict = internal.Error("module version conflict")
+
+	// ErrRequiredModule is raised when the required module is missing.
+	ErrRequiredModule = internal.Error("required module is missing")
+
+	// ErrModuleConflict is raised when different modules are combined with the same name and version.
+	ErrModuleConflict = internal.Error("module name conflict")
+)
+
+// Error returns all problems as readable list.
+func (e Errors) Error() string {
+	list := make([]string, 0, len(e))
+	for _, err := range e {
+		list = append(list, err.Error())
+	}
+
+	return strings.Join(list, "\n")
+}
+
+// Is allows to check any of problems by errors.Is.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns name and version of the module.
+func (i *Info) String() string {
+	if i.Version == "" {
+		return i.Name
+	}
+
+	return i.Name + "@" + i.Version
+}
+
 // New single module.
 func New(fn interface{}, opts ...dig.ProvideOption) Module {
 	return Module{
@@ -25,11 +98,86 @@ func New(fn interface{}, opts ...dig.ProvideOption) Module {
 	}
 }
 
+// Named creates module with passed info, every provider of the module is attributed to it.
+// Providers that already belong to other named modules (nested modules) keep their attribution.
+// Named modules are known by Suggest.
+func Named(info Info, mods ...Module) Module {
+	var result, own Module
+
+	for _, p := range Combine(mods...) {
+		if p != nil && p.Module == nil {
+			clone := *p
+			clone.Module = &info
+			p = &clone
+
+			own = append(own, p)
+		}
+
+		result = append(result, p)
+	}
+
+	register(&info, own)
+
+	return result
+}
+
 // Combine multiple modules into new one.
+// Identical providers and named modules, that were created twice with the same name, version and providers,
+// are added only once. Different modules with the same name and version are kept, they are reported by Check.
 func Combine(mods ...Module) Module {
-	var result Module
+	var (
+		list Module
+
+		seen  = make(map[*Provider]struct{})
+		infos []*Info
+		own   = make(map[*Info]Module)
+	)
+
 	for _, mod := range mods {
-		result = append(result, mod...)
+		for _, p := range mod {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+
+			seen[p] = struct{}{}
+			list = append(list, p)
+
+			if p == nil || p.Module == nil {
+				continue
+			}
+
+			if _, ok := own[p.Module]; !ok {
+				infos = append(infos, p.Module)
+			}
+
+			own[p.Module] = append(own[p.Module], p)
+		}
+	}
+
+	var (
+		first     = make(map[string]*Info)
+		duplicate = make(map[*Info]struct{})
+	)
+
+	for _, info := range infos {
+		if prev, ok := first[info.String()]; !ok {
+			first[info.String()] = info
+		} else if prev.same(info) && own[prev].same(own[info]) {
+			// the same module was created twice
+			duplicate[info] = struct{}{}
+		}
+	}
+
+	result := make(Module, 0, len(list))
+
+	for _, p := range list {
+		if p != nil && p.Module != nil {
+			if _, ok := duplicate[p.Module]; ok {
+				continue
+			}
+		}
+
+		result = append(result, p)
 	}
 
 	return result
@@ -37,12 +185,7 @@ func Combine(mods ...Module) Module {
 
 // Append module to target module and return new module.
 func (m Module) Append(mods ...Module) Module {
-	result := m
-	for _, mod := range mods {
-		result = append(result, mod...)
-	}
-
-	return result
+	return Combine(append([]Module{m}, mods...)...)
 }
 
 // AppendConstructor adds constructors into the module and return new Module.
@@ -55,13 +198,208 @@ func (m Module) AppendConstructor(constructors ...interface{}) Module {
 	return m.Append(modules...)
 }
 
+// Check reports conflicting providers, modules combined with different versions, different modules
+// with the same name and version and missing required modules.
+// Every problem contains the module, that introduced the provider.
+func (m Module) Check() error {
+	var (
+		problems Errors
+
+		names     []string
+		modules   = make(map[string][]*Info)
+		providers = make(map[string][]*Provider)
+
+		defined   = make(map[string]*Info)
+		conflicts []string
+	)
+
+	for _, p := range m {
+		if p == nil {
+			continue
+		}
+
+		if p.Module != nil {
+			if _, ok := modules[p.Module.Name]; !ok {
+				names = append(names, p.Module.Name)
+			}
+
+			modules[p.Module.Name] = appendInfo(modules[p.Module.Name], p.Module)
+
+			if info, ok := defined[p.Module.String()]; !ok {
+				defined[p.Module.String()] = p.Module
+			} else if info != p.Module && !contains(conflicts, info.String()) {
+				conflicts = append(conflicts, info.String())
+			}
+		}
+
+		for _, out := range outputs(p) {
+			providers[out] = append(providers[out], p)
+		}
+	}
+
+	for _, name := range names {
+		if list := modules[name]; len(list) > 1 {
+			versions := make([]string, 0, len(list))
+			for _, info := range list {
+				versions = append(versions, info.String())
+			}
+
+			problems = append(problems, fmt.Errorf("%w: %s", ErrVersionConflict, strings.Join(versions, ", ")))
+		}
+
+		for _, required := range modules[name][0].Requires {
+			if _, ok := modules[required]; !ok {
+				problems = append(problems, fmt.Errorf("%w: %q required by %s", ErrRequiredModule, required, modules[name][0]))
+			}
+		}
+	}
+
+	for _, name := range conflicts {
+		problems = append(problems, fmt.Errorf("%w: %s is defined by different modules", ErrModuleConflict, name))
+	}
+
+	types := make([]string, 0, len(providers))
+	for out := range providers {
+		if len(providers[out]) > 1 {
+			types = append(types, out)
+		}
+	}
+
+	sort.Strings(types)
+
+	for _, out := range types {
+		list := make([]string, 0, len(providers[out]))
+		for _, p := range providers[out] {
+			list = append(list, Origin(p))
+		}
+
+		problems = append(problems, fmt.Errorf("%w: %s is provided by %s", ErrProviderConflict, out, strings.Join(list, ", ")))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return problems
+}
+
+// skip returns problems without errors of passed kind.
+func (e Errors) skip(target error) Errors {
+	var result Errors
+
+	for _, err := range e {
+		if !errors.Is(err, target) {
+			result = append(result, err)
+		}
+	}
+
+	return result
+}
+
+// Origin returns the constructor of the provider and the module, that introduced it.
+func Origin(p *Provider) string {
+	name, pkg := constructorName(p.Constructor)
+	if pkg != "" {
+		name = pkg + "." + name
+	}
+
+	if p.Module != nil {
+		return fmt.Sprintf("%s (module %s)", name, p.Module)
+	}
+
+	return name
+}
+
 // Provide set providers functions to DI container.
+// Overrides replace providers of the same types and decorators are applied after all providers.
+// Invoke functions are not called, see Module.Invoke.
 func Provide(dic *dig.Container, providers Module) error {
-	for _, p := range providers {
-		if err := dic.Provide(p.Constructor, p.Options...); err != nil {
-			return err
+	return provide(dic, providers, func(p *Provider) error {
+		return dic.Provide(p.Constructor, p.Options...)
+	})
+}
+
+// outputs returns types provided by the provider, value groups are ignored, because they could be provided many times.
+func outputs(p *Provider) []string {
+	if p.Constructor == nil || p.kind == kindDecorator || p.kind == kindInvoke || p.kind == kindSetup {
+		return nil
+	}
+
+	info := new(dig.ProvideInfo)
+	options := append(append([]dig.ProvideOption(nil), p.Options...), dig.FillProvideInfo(info))
+
+	if err := dig.New(dig.DryRun(true)).Provide(p.Constructor, options...); err != nil {
+		// will be reported by DI container
+		return nil
+	}
+
+	result := make([]string, 0, len(info.Outputs))
+	for _, out := range info.Outputs {
+		if key := out.String(); !strings.Contains(key, "group = ") {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
+
+// constructorName returns name and package of the constructor.
+func constructorName(constructor interface{}) (string, string) {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", constructor), ""
+	}
+
+	info := runtime.FuncForPC(fn.Pointer())
+	if info == nil {
+		return fn.Type().String(), ""
+	}
+
+	return splitName(info.Name())
+}
+
+// same returns true, when modules have the same name, version and required modules.
+func (i *Info) same(info *Info) bool {
+	return i.String() == info.String() && reflect.DeepEqual(i.Requires, info.Requires)
+}
+
+// same returns true, when providers have the same constructors and kinds, e.g. the module was created twice.
+func (m Module) same(other Module) bool {
+	if len(m) != len(other) {
+		return false
+	}
+
+	for i := range m {
+		a, b := m[i], other[i]
+		if a.kind != b.kind || len(a.Options) != len(b.Options) || len(a.conditions) != len(b.conditions) {
+			return false
+		}
+
+		if fa, fb := reflect.ValueOf(a.Constructor), reflect.ValueOf(b.Constructor); fa.Kind() != reflect.Func ||
+			fb.Kind() != reflect.Func || fa.Pointer() != fb.Pointer() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(list []string, item string) bool {
+	for i := range list {
+		if list[i] == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendInfo(list []*Info, info *Info) []*Info {
+	for _, item := range list {
+		if item.Version == info.Version {
+			return list
 		}
 	}
 
-	return nil
+	return append(list, info)
 }
@@ -114,3 +114,76 @@ func TestModule(t *testing.T) {
 		})
 	})
 }
+
+func newNamedLogger() *string { return new(string) }
+
+func TestNamed(t *testing.T) {
+	logger := func(version string) Module {
+		return Named(Info{Name: "logger", Version: version}, New(newNamedLogger))
+	}
+
+	t.Run("should attribute providers to the module", func(t *testing.T) {
+		inner := logger("v1.0.0")
+		outer := Named(Info{Name: "app"}, inner, New(func() int { return 0 }))
+
+		require.Len(t, outer, 2)
+		require.Equal(t, "logger@v1.0.0", outer[0].Module.String())
+		require.Equal(t, "app", outer[1].Module.String())
+	})
+
+	t.Run("should deduplicate identical providers and modules", func(t *testing.T) {
+		shared := New(func() int { return 0 })
+
+		result := Combine(shared, shared, logger("v1.0.0"), logger("v1.0.0"))
+		require.Len(t, result, 2)
+		require.NoError(t, Provide(dig.New(), result))
+
+		require.Len(t, shared.Append(shared), 1)
+	})
+
+	t.Run("should report different modules with the same name", func(t *testing.T) {
+		routes := func(constructor interface{}) Module {
+			return Named(Info{Name: "routes"}, New(constructor))
+		}
+
+		result := Combine(routes(func() int { return 0 }), routes(func() string { return "" }))
+		require.Len(t, result, 2, "different modules should be kept")
+
+		err := Provide(dig.New(), result)
+		require.ErrorIs(t, err, ErrModuleConflict)
+		require.Equal(t, "module name conflict: routes is defined by different modules", err.Error())
+
+		require.ErrorIs(t, Combine(logger(""), Named(Info{Name: "logger", Requires: []string{"settings"}},
+			New(newNamedLogger))).Check(), ErrModuleConflict)
+	})
+
+	t.Run("should report modules with different versions", func(t *testing.T) {
+		err := Provide(dig.New(), Combine(logger("v1.0.0"), logger("v2.0.0")))
+		require.ErrorIs(t, err, ErrVersionConflict)
+		require.Contains(t, err.Error(), "logger@v1.0.0, logger@v2.0.0")
+	})
+
+	t.Run("should report missing required modules", func(t *testing.T) {
+		app := Named(Info{Name: "app", Requires: []string{"logger", "settings"}}, New(func() int { return 0 }))
+
+		err := Provide(dig.New(), Combine(app, logger("")))
+		require.ErrorIs(t, err, ErrRequiredModule)
+		require.Equal(t, `required module is missing: "settings" required by app`, err.Error())
+	})
+
+	t.Run("should report conflicting providers with modules", func(t *testing.T) {
+		err := Provide(dig.New(), Combine(
+			logger("v1.0.0"),
+			New(newNamedLogger, dig.Name("named")),    // named values don't conflict
+			New(newNamedLogger, dig.Group("loggers")), // groups don't conflict
+			New(func() *string { return nil })))
+		require.ErrorIs(t, err, ErrProviderConflict)
+		require.Contains(t, err.Error(), "*string is provided by "+
+			"github.com/im-kulikov/helium/module.newNamedLogger (module logger@v1.0.0), "+
+			"github.com/im-kulikov/helium/module.TestNamed.func")
+
+		var errs Errors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 1)
+	})
+}
@@ -0,0 +1,175 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+type kind int
+
+const (
+	kindProvider kind = iota
+	kindOverride
+	kindDecorator
+	kindInvoke
+	kindSetup
+)
+
+// ErrOverride is raised when override removes provider, that also provides other types.
+const ErrOverride = internal.Error("could not override provider")
+
+// Override creates module, that replaces providers of the same types (see Module.Resolve).
+// It allows to swap constructors in tests or for specific environments without rewriting modules.
+func Override(constructor interface{}, opts ...dig.ProvideOption) Module {
+	return Module{{Constructor: constructor, Options: opts, kind: kindOverride}}
+}
+
+// Decorate creates module, that wraps values provided by other constructors (see dig.Decorate).
+// Decorator accepts values and returns the values of the same types.
+func Decorate(decorator interface{}, opts ...dig.DecorateOption) Module {
+	return Module{{Constructor: decorator, kind: kindDecorator, decorate: opts}}
+}
+
+// Resolve returns providers, where providers of types returned by overrides are removed,
+// decorators are moved to the end, so they are applied when all constructors are provided.
+func (m Module) Resolve() (Module, error) {
+	overridden := make(map[string]*Provider)
+
+	for _, p := range m {
+		if p == nil || p.kind != kindOverride {
+			continue
+		}
+
+		for _, out := range outputs(p) {
+			overridden[out] = p
+		}
+	}
+
+	var (
+		result     = make(Module, 0, len(m))
+		decorators Module
+	)
+
+	for _, p := range m {
+		if p == nil {
+			result = append(result, p)
+
+			continue
+		}
+
+		switch p.kind {
+		case kindProvider:
+		case kindDecorator:
+			decorators = append(decorators, p)
+
+			continue
+		case kindOverride, kindInvoke, kindSetup:
+			result = append(result, p)
+
+			continue
+		}
+
+		var replaced, kept []string
+
+		for _, out := range outputs(p) {
+			if _, ok := overridden[out]; ok {
+				replaced = append(replaced, out)
+			} else {
+				kept = append(kept, out)
+			}
+		}
+
+		switch {
+		case len(replaced) == 0:
+			result = append(result, p)
+		case len(kept) > 0:
+			return nil, fmt.Errorf("%w: %s is overridden by %s, but also provides %s",
+				ErrOverride, Origin(p), Origin(overridden[replaced[0]]), strings.Join(kept, ", "))
+		}
+	}
+
+	return append(result, decorators...), nil
+}
+
+// provide enables conditional providers, resolves overrides, checks providers and passes them into DI container,
+// decorators are applied after all constructors. Unconditional providers, that could not be replaced
+// by conditional ones, are passed first, so conditions are checked against settings from the same container.
+// Setup functions are called before conditions are checked or after all providers, when there are no conditions.
+func provide(dic *dig.Container, providers Module, fn func(*Provider) error) error {
+	first, rest := providers.stage()
+	conditional := rest.conditional()
+
+	first, err := first.Resolve()
+	if err != nil {
+		return err
+	}
+
+	// required modules could be provided by the rest of providers, they are checked below
+	var problems Errors
+	if errors.As(first.Check(), &problems) {
+		if problems = problems.skip(ErrRequiredModule); len(problems) > 0 {
+			return problems
+		}
+	}
+
+	if err = pass(dic, first, fn); err != nil {
+		return err
+	}
+
+	if conditional {
+		if err = setup(dic, rest); err != nil {
+			return err
+		}
+	}
+
+	if rest, err = Enable(dic, rest); err != nil {
+		return err
+	}
+
+	if rest, err = rest.Resolve(); err != nil {
+		return err
+	}
+
+	// required modules could be provided by any of stages
+	if err = append(first, rest...).Check(); err != nil {
+		return err
+	}
+
+	if err = pass(dic, rest, fn); err != nil {
+		return err
+	}
+
+	if conditional {
+		return nil
+	}
+
+	return setup(dic, rest)
+}
+
+// pass passes providers into DI container, invoke and setup functions are skipped.
+func pass(dic *dig.Container, providers Module, fn func(*Provider) error) error {
+	for _, p := range providers {
+		var err error
+
+		switch {
+		case p != nil && (p.kind == kindInvoke || p.kind == kindSetup):
+			// called by Module.Invoke and provide
+			continue
+		case p != nil && p.kind == kindDecorator:
+			err = dic.Decorate(p.Constructor, p.decorate...)
+		default:
+			err = fn(p)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
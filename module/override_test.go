@@ -0,0 +1,100 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+)
+
+type (
+	overrideHandler interface{ Name() string }
+
+	overrideName string
+
+	overrideMiddleware struct{ next overrideHandler }
+)
+
+func (n overrideName) Name() string { return string(n) }
+
+func (m overrideMiddleware) Name() string { return "middleware(" + m.next.Name() + ")" }
+
+func newOverrideHandler() overrideHandler { return overrideName("original") }
+
+func TestOverride(t *testing.T) {
+	original := Named(Info{Name: "web"}, New(newOverrideHandler), New(func() int { return 1 }))
+
+	t.Run("should replace provider by type", func(t *testing.T) {
+		dic := dig.New()
+		require.NoError(t, Provide(dic, Combine(
+			Override(func() overrideHandler { return overrideName("override") }),
+			original)))
+
+		require.NoError(t, dic.Invoke(func(h overrideHandler, v int) {
+			require.Equal(t, "override", h.Name())
+			require.Equal(t, 1, v)
+		}))
+	})
+
+	t.Run("should keep overrides in named modules", func(t *testing.T) {
+		resolved, err := Combine(original, Named(Info{Name: "test"},
+			Override(func() overrideHandler { return overrideName("override") }))).Resolve()
+		require.NoError(t, err)
+		require.Len(t, resolved, 2)
+		require.Equal(t, "test", resolved[1].Module.String())
+	})
+
+	t.Run("should not remove provider of other types", func(t *testing.T) {
+		err := Provide(dig.New(), Combine(
+			Named(Info{Name: "web"}, New(func() (overrideHandler, int) { return nil, 0 })),
+			Override(func() int { return 2 })))
+		require.ErrorIs(t, err, ErrOverride)
+		require.Contains(t, err.Error(), "(module web) is overridden by")
+		require.Contains(t, err.Error(), "but also provides module.overrideHandler")
+	})
+
+	t.Run("should report conflicting overrides", func(t *testing.T) {
+		err := Provide(dig.New(), Combine(
+			Override(func() int { return 2 }),
+			Override(func() int { return 3 })))
+		require.ErrorIs(t, err, ErrProviderConflict)
+	})
+}
+
+func TestDecorate(t *testing.T) {
+	decorator := Decorate(func(next overrideHandler) overrideHandler {
+		return overrideMiddleware{next: next}
+	})
+
+	t.Run("should decorate provided value", func(t *testing.T) {
+		dic := dig.New()
+		require.NoError(t, Provide(dic, Combine(decorator, New(newOverrideHandler))))
+
+		require.NoError(t, dic.Invoke(func(h overrideHandler) {
+			require.Equal(t, "middleware(original)", h.Name())
+		}))
+	})
+
+	t.Run("should decorate overridden value", func(t *testing.T) {
+		dic := dig.New()
+		tracker := NewTracker()
+		require.NoError(t, tracker.Provide(dic, Combine(
+			New(newOverrideHandler),
+			decorator,
+			Override(func() overrideHandler { return overrideName("override") }))))
+
+		require.NoError(t, dic.Invoke(func(h overrideHandler) {
+			require.Equal(t, "middleware(override)", h.Name())
+		}))
+
+		graph := tracker.Graph()
+		require.Len(t, graph.Nodes, 1)
+		require.True(t, graph.Nodes[0].Constructed)
+	})
+
+	t.Run("should fail on missing type", func(t *testing.T) {
+		dic := dig.New()
+		require.NoError(t, Provide(dic, decorator))
+		require.Error(t, dic.Invoke(func(overrideHandler) {}))
+	})
+}
@@ -0,0 +1,80 @@
+package module
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+type known struct {
+	info      *Info
+	providers Module
+	outputs   []string
+}
+
+// nolint:gochecknoglobals
+var registry = struct {
+	sync.Mutex
+
+	modules []*known
+}{}
+
+// register adds the named module into the registry, that is used by Suggest.
+func register(info *Info, providers Module) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, item := range registry.modules {
+		if item.info.Name == info.Name {
+			return
+		}
+	}
+
+	registry.modules = append(registry.modules, &known{info: info, providers: providers})
+}
+
+// Suggest returns names of named modules (see Named), that provide passed type, e.g. `*zap.Logger`.
+// Names of values are ignored, so `*zap.Logger[name="x"]` is the same type.
+func Suggest(typ string) []string {
+	registry.Lock()
+	defer registry.Unlock()
+
+	var result []string
+
+	typ = baseType(typ)
+
+	for _, item := range registry.modules {
+		if item.outputs == nil {
+			item.outputs = []string{}
+
+			for _, p := range item.providers {
+				if p != nil {
+					item.outputs = append(item.outputs, outputs(p)...)
+				}
+			}
+		}
+
+		for _, out := range item.outputs {
+			if baseType(out) == typ {
+				result = append(result, item.info.Name)
+
+				break
+			}
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// baseType returns the type without name or group annotation.
+func baseType(typ string) string {
+	for _, annotation := range []string{"[name", "[group"} {
+		if idx := strings.LastIndex(typ, annotation); idx > 0 {
+			return typ[:idx]
+		}
+	}
+
+	return typ
+}
@@ -0,0 +1,30 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+)
+
+type (
+	registryClient struct{}
+	registryApp    struct{}
+	registryConfig map[string]int
+)
+
+func TestSuggest(t *testing.T) {
+	Named(Info{Name: "registry.ClientModule"},
+		New(func() *registryClient { return nil }),
+		New(func() registryConfig { return nil }, dig.Name("config")),
+		Invoke(func() *registryClient { return nil }))
+
+	Named(Info{Name: "registry.AppModule"},
+		Named(Info{Name: "registry.ClientModule"}, New(func() *registryClient { return nil })),
+		New(func(*registryClient) registryApp { return registryApp{} }))
+
+	require.Equal(t, []string{"registry.ClientModule"}, Suggest("*module.registryClient"))
+	require.Equal(t, []string{"registry.ClientModule"}, Suggest(`module.registryConfig[name="config"]`))
+	require.Equal(t, []string{"registry.AppModule"}, Suggest("module.registryApp"))
+	require.Empty(t, Suggest("*module.unknown"))
+}
@@ -6,29 +6,47 @@ import (
 	"github.com/spf13/viper"
 	"go.uber.org/dig"
 
+	"github.com/im-kulikov/helium/group"
 	"github.com/im-kulikov/helium/module"
 )
 
 type outParams struct {
 	dig.Out
 
-	Shutdown time.Duration `name:"service_shutdown_timeout"`
+	Shutdown time.Duration       `name:"service_shutdown_timeout"`
+	Restart  group.RestartConfig `name:"service_restart"`
+	Configs  []Config            `group:"service_configs,flatten"`
 }
 
-// ShutdownTimeoutParam name for viper setting.
-const ShutdownTimeoutParam = "shutdown_timeout"
+const (
+	// ShutdownTimeoutParam name for viper setting.
+	ShutdownTimeoutParam = "shutdown_timeout"
+
+	// ServicesParam name for viper setting, that contains list of services configs.
+	ServicesParam = "services"
+
+	// RestartParam name for viper setting, that contains default restart policy of services.
+	RestartParam = "restart"
+)
 
 var (
 	_ = Module // prevent unused
 
 	// Module for group of services
 	// nolint:gochecknoglobals
-	Module = module.Module{
+	Module = module.Named(module.Info{Name: "service.Module"}, module.Module{
 		{Constructor: newParam},
+		{Constructor: NewMonitor},
 		{Constructor: newGroup},
-	}
+	})
 )
 
-func newParam(v *viper.Viper) outParams {
-	return outParams{Shutdown: v.GetDuration(ShutdownTimeoutParam)}
+func newParam(v *viper.Viper) (outParams, error) {
+	out := outParams{Shutdown: v.GetDuration(ShutdownTimeoutParam)}
+
+	if err := v.UnmarshalKey(RestartParam, &out.Restart); err != nil {
+		return out, err
+	}
+
+	return out, v.UnmarshalKey(ServicesParam, &out.Configs)
 }
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/im-kulikov/helium/group"
+	"github.com/im-kulikov/helium/internal"
+)
+
+// Monitor collects failures of optional services and states of all services.
+// It allows to check health of the services group, for example, by ops-server health probe.
+type Monitor struct {
+	mu       sync.RWMutex
+	failed   map[string]error
+	failures *prometheus.CounterVec
+	states   func() []group.ServiceState
+}
+
+// ErrServiceFailed is raised by Monitor.Check when any optional service has failed.
+const ErrServiceFailed = internal.Error("services failed")
+
+// NewMonitor creates Monitor of services, failures are counted by `helium_service_failures_total` metric.
+func NewMonitor() *Monitor {
+	return &Monitor{
+		failed: make(map[string]error),
+		failures: internal.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "helium",
+			Subsystem: "service",
+			Name:      "failures_total",
+			Help:      "Count of failures of optional services.",
+		}, []string{"service"})),
+	}
+}
+
+// Check returns an error when any optional service has failed.
+func (m *Monitor) Check(context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.failed) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(m.failed))
+	for name := range m.failed {
+		names = append(names, name+": "+m.failed[name].Error())
+	}
+
+	sort.Strings(names)
+
+	return fmt.Errorf("%w: %s", ErrServiceFailed, strings.Join(names, "; "))
+}
+
+// Failed returns errors of failed optional services by their names.
+func (m *Monitor) Failed() map[string]error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string]error, len(m.failed))
+	for name, err := range m.failed {
+		result[name] = err
+	}
+
+	return result
+}
+
+// Fail reports failure of the service.
+func (m *Monitor) Fail(name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failed[name] = err
+
+	m.failures.WithLabelValues(name).Inc()
+}
+
+// Clear forgets failure of the service, it is called when the service is running again
+// (for example, it was attached to the group after failure).
+func (m *Monitor) Clear(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.failed, name)
+}
+
+// States returns current states of services, it returns nil until services group is created.
+func (m *Monitor) States() []group.ServiceState {
+	m.mu.RLock()
+	states := m.states
+	m.mu.RUnlock()
+
+	if states == nil {
+		return nil
+	}
+
+	return states()
+}
+
+// watch sets the source of services states.
+func (m *Monitor) watch(states func() []group.ServiceState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.states = states
+}
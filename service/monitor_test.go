@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/im-kulikov/helium/group"
+)
+
+func TestMonitor(t *testing.T) {
+	monitor := NewMonitor()
+	require.NoError(t, monitor.Check(context.Background()))
+	require.Empty(t, monitor.Failed())
+
+	// metric is shared by monitors
+	failures := NewMonitor().failures
+	before := testutil.ToFloat64(failures.WithLabelValues("second"))
+
+	monitor.Fail("second", testError)
+	monitor.Fail("first", testError)
+
+	err := monitor.Check(context.Background())
+	require.ErrorIs(t, err, ErrServiceFailed)
+	require.EqualError(t, err, "services failed: first: test error; second: test error")
+	require.Equal(t, map[string]error{"first": testError, "second": testError}, monitor.Failed())
+	require.Equal(t, before+1, testutil.ToFloat64(failures.WithLabelValues("second")))
+
+	monitor.Clear("first")
+	monitor.Clear("second")
+	require.NoError(t, monitor.Check(context.Background()))
+	require.Equal(t, before+1, testutil.ToFloat64(failures.WithLabelValues("second")), "failures are still counted")
+}
+
+func TestMonitorStates(t *testing.T) {
+	monitor := NewMonitor()
+	require.Nil(t, monitor.States())
+
+	wrk := newWorker()
+	grp := newGroup(Params{
+		Group:   []Service{wrk},
+		Logger:  zaptest.NewLogger(t),
+		Monitor: monitor,
+	})
+
+	states := monitor.States()
+	require.Len(t, states, 1)
+	require.Equal(t, wrk.Name(), states[0].Name)
+	require.Equal(t, group.StateCreated, states[0].State)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- grp.Run(ctx) }()
+
+	<-grp.Ready()
+	require.Equal(t, group.StateRunning, monitor.States()[0].State)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, group.StateStopped, monitor.States()[0].State)
+}
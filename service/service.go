@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/im-kulikov/helium/group"
+	"github.com/im-kulikov/helium/internal"
 )
 
 type (
@@ -22,9 +23,54 @@ type (
 		Name() string
 	}
 
+	// Readiness is an optional interface for Service,
+	// that allows to signal when service is actually up (for example, listener is serving).
+	// Services that depend on it will be started only after Ready channel is closed.
+	Readiness interface {
+		Ready() <-chan struct{}
+	}
+
+	// Shutdowner is an optional interface for Service, that allows to report an error on stop.
+	// When service implements it, Shutdown is called instead of Stop.
+	Shutdowner interface {
+		Shutdown(context.Context) error
+	}
+
 	// Group wrapper around group of services.
+	// Ready channel will be closed when all services are up.
+	// States returns current states of all services.
+	// Attach and Detach allow to add and gracefully stop services (by name) of the running group.
 	Group interface {
 		Run(context.Context) error
+		Ready() <-chan struct{}
+		States() []group.ServiceState
+		Attach(Service) error
+		Detach(context.Context, string) error
+	}
+
+	// Config allows to declare when the service (found by name) should be started.
+	Config struct {
+		// Name of the service, see Service.Name.
+		Name string `mapstructure:"name"`
+
+		// After contains names of services that should be up before the service starts.
+		After []string `mapstructure:"after"`
+
+		// Phase of the service, services of the phase will be started
+		// when all services of previous phases are up.
+		Phase int `mapstructure:"phase"`
+
+		// Restart allows to run the service in supervisor mode,
+		// it overrides default restart policy of services.
+		Restart *group.RestartConfig `mapstructure:"restart"`
+
+		// Optional service does not stop other services when it fails,
+		// its failure is logged and reported by Monitor.
+		Optional bool `mapstructure:"optional"`
+
+		// ShutdownTimeout of the service, it overrides default shutdown timeout of services.
+		// Service that was not stopped in time is abandoned.
+		ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 	}
 
 	// Params for service module.
@@ -32,23 +78,46 @@ type (
 		dig.In
 
 		Logger   *zap.Logger
-		Group    []Service     `group:"services"`
-		Shutdown time.Duration `name:"service_shutdown_timeout"`
+		Monitor  *Monitor            `optional:"true"`
+		Group    []Service           `group:"services"`
+		Configs  []Config            `group:"service_configs"`
+		Shutdown time.Duration       `name:"service_shutdown_timeout"`
+		Restart  group.RestartConfig `name:"service_restart" optional:"true"`
 	}
 
 	multiple struct {
 		*zap.Logger
 		group.Service
+
+		monitor *Monitor
+		configs map[string]Config
 	}
 )
 
+// ErrEmptyService is raised when nil service is attached to the group.
+const ErrEmptyService = internal.Error("empty service")
+
 // create group of services.
 func newGroup(p Params) Group {
-	run := &multiple{
-		Logger:  p.Logger,
-		Service: group.New(group.WithShutdownTimeout(p.Shutdown)),
+	if p.Monitor == nil {
+		p.Monitor = NewMonitor()
+	}
+
+	configs := make(map[string]Config, len(p.Configs))
+	for _, cfg := range p.Configs {
+		configs[cfg.Name] = configs[cfg.Name].merge(cfg)
 	}
 
+	run := &multiple{Logger: p.Logger, monitor: p.Monitor, configs: configs}
+	run.Service = group.New(
+		group.WithShutdownTimeout(p.Shutdown),
+		group.WithRestart(p.Restart),
+		group.WithFailureHandler(run.failed),
+		group.WithPanicHandler(run.panicked),
+		group.WithShutdownReporter(run.stopped))
+
+	p.Monitor.watch(run.States)
+
 	p.Logger.Info("added workers", zap.Int("count", len(p.Group)))
 
 	for i := range p.Group {
@@ -58,24 +127,177 @@ func newGroup(p Params) Group {
 			continue
 		}
 
-		run.Add(run.prepareActor(p.Group[i]))
+		callback, shutdown, options := run.prepare(p.Group[i])
+
+		run.Add(callback, shutdown, options...)
 	}
 
 	return run
 }
 
+// Attach adds the service to the group, when the group is running the service starts immediately.
+// Config of the service (found by name) is applied, the same as for services provided into DI.
+func (m *multiple) Attach(svc Service) error {
+	if svc == nil {
+		return ErrEmptyService
+	}
+
+	callback, shutdown, options := m.prepare(svc)
+
+	return m.Service.Attach(callback, shutdown, options...)
+}
+
+// Detach gracefully stops the service (found by name) and removes it from the group.
+func (m *multiple) Detach(ctx context.Context, name string) error {
+	m.Info("detach service", zap.String("name", name))
+
+	return m.Service.Detach(ctx, name)
+}
+
+// prepare returns callback, shutdown and options of the service.
+func (m *multiple) prepare(svc Service) (group.Callback, group.Shutdown, []group.ServiceOption) {
+	callback, shutdown := m.prepareActor(svc)
+	options := m.configs[svc.Name()].options(svc.Name())
+
+	if _, ok := svc.(Readiness); ok {
+		options = append(options, group.WaitReady())
+	}
+
+	return callback, shutdown, options
+}
+
+// merge combines configs of the same service.
+func (c Config) merge(cfg Config) Config {
+	c.Name = cfg.Name
+	c.After = append(c.After, cfg.After...)
+
+	if cfg.Phase != 0 {
+		c.Phase = cfg.Phase
+	}
+
+	if cfg.Restart != nil {
+		c.Restart = cfg.Restart
+	}
+
+	if cfg.ShutdownTimeout != 0 {
+		c.ShutdownTimeout = cfg.ShutdownTimeout
+	}
+
+	c.Optional = c.Optional || cfg.Optional
+
+	return c
+}
+
+func (c Config) options(name string) []group.ServiceOption {
+	options := []group.ServiceOption{
+		group.Name(name),
+		group.After(c.After...),
+		group.StartPhase(c.Phase),
+	}
+
+	if c.Restart != nil {
+		options = append(options, group.Restart(*c.Restart))
+	}
+
+	if c.Optional {
+		options = append(options, group.Optional())
+	}
+
+	if c.ShutdownTimeout > 0 {
+		options = append(options, group.ShutdownTimeout(c.ShutdownTimeout))
+	}
+
+	return options
+}
+
 func (m *multiple) prepareActor(svc Service) (group.Callback, group.Shutdown) {
 	m.Info("add service", zap.String("name", svc.Name()))
 
 	return func(ctx context.Context) error {
 			m.Info("run service", zap.String("name", svc.Name()))
 
+			if ready, ok := svc.(Readiness); ok {
+				go m.notifyReady(ctx, svc.Name(), ready.Ready())
+			} else {
+				m.monitor.Clear(svc.Name())
+			}
+
 			return svc.Start(ctx)
 		},
 
-		func(ctx context.Context) {
+		func(ctx context.Context) error {
 			m.Info("stop service", zap.String("name", svc.Name()))
 
-			svc.Stop(ctx)
+			stopper, ok := svc.(Shutdowner)
+			if !ok {
+				svc.Stop(ctx)
+
+				return nil
+			}
+
+			err := stopper.Shutdown(ctx)
+			if err != nil {
+				m.Error("could not stop service",
+					zap.String("name", svc.Name()),
+					zap.Error(err))
+			}
+
+			return err
+		}
+}
+
+// failed logs and reports failure of the optional service.
+func (m *multiple) failed(name string, err error) {
+	m.Error("optional service failed",
+		zap.String("name", name),
+		zap.Error(err))
+
+	m.monitor.Fail(name, err)
+}
+
+// panicked logs recovered panic of the service.
+func (m *multiple) panicked(err *group.PanicError) {
+	m.Error("service panicked",
+		zap.String("name", err.Service),
+		zap.Any("panic", err.Value),
+		zap.ByteString("stack", err.Stack))
+}
+
+// stopped logs how long it took to stop every service and which services were abandoned.
+func (m *multiple) stopped(report []group.ShutdownResult) {
+	var abandoned []string
+
+	for _, res := range report {
+		if !res.Abandoned {
+			m.Info("service stopped",
+				zap.String("name", res.Service),
+				zap.Duration("duration", res.Duration))
+
+			continue
 		}
+
+		abandoned = append(abandoned, res.Service)
+
+		m.Warn("service was not stopped in time and was abandoned",
+			zap.String("name", res.Service),
+			zap.Duration("budget", res.Budget),
+			zap.Duration("duration", res.Duration))
+	}
+
+	if len(abandoned) > 0 {
+		m.Warn("some services were abandoned on shutdown", zap.Strings("services", abandoned))
+	}
+}
+
+func (m *multiple) notifyReady(ctx context.Context, name string, ready <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+	case <-ready:
+		m.Info("service is ready", zap.String("name", name))
+
+		// the service is running again, so its previous failure is not actual
+		m.monitor.Clear(name)
+
+		group.NotifyReady(ctx)
+	}
 }
@@ -13,7 +13,9 @@ import (
 	"go.uber.org/dig"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 
+	"github.com/im-kulikov/helium/group"
 	"github.com/im-kulikov/helium/internal"
 	"github.com/im-kulikov/helium/module"
 )
@@ -25,6 +27,21 @@ type (
 		number  int
 		errored bool
 		started *atomic.Bool
+		ready   chan struct{}
+	}
+
+	testShutdowner struct {
+		*testWorker
+	}
+
+	testPanic struct {
+		*testWorker
+	}
+
+	testStuck struct {
+		*testWorker
+
+		release chan struct{}
 	}
 
 	testServiceOut struct {
@@ -46,7 +63,11 @@ const testError = internal.Error("test error")
 var (
 	iter = atomic.NewInt32(0)
 
-	_ Service = (*testWorker)(nil)
+	_ Service   = (*testWorker)(nil)
+	_ Readiness = (*testWorker)(nil)
+
+	_ Shutdowner = (*testShutdowner)(nil)
+	_ Shutdowner = (*testStuck)(nil)
 )
 
 func (t *testWorker) Start(ctx context.Context) error {
@@ -55,12 +76,15 @@ func (t *testWorker) Start(ctx context.Context) error {
 	}
 
 	t.started.Toggle()
+	close(t.ready)
 
 	<-ctx.Done()
 
 	return nil
 }
 
+func (t *testWorker) Ready() <-chan struct{} { return t.ready }
+
 func (t *testWorker) Stop(context.Context) {
 	if t.errored {
 		t.Store(testError)
@@ -69,6 +93,20 @@ func (t *testWorker) Stop(context.Context) {
 	t.started.Toggle()
 }
 
+func (t *testShutdowner) Shutdown(ctx context.Context) error {
+	t.Stop(ctx)
+
+	return t.Load()
+}
+
+func (t *testStuck) Shutdown(context.Context) error {
+	<-t.release
+
+	return nil
+}
+
+func (t *testPanic) Start(context.Context) error { panic(testError.Error()) }
+
 func (t *testWorker) Name() string {
 	return "test-worker-" + strconv.Itoa(t.number)
 }
@@ -78,6 +116,7 @@ func newWorker() *testWorker {
 		number:  int(iter.Inc()),
 		Error:   atomic.NewError(nil),
 		started: atomic.NewBool(false),
+		ready:   make(chan struct{}),
 	}
 }
 
@@ -97,7 +136,7 @@ func TestServices(t *testing.T) {
 		require.NoError(t, module.Provide(di, Module.Append(module.Module{
 			{Constructor: func() *viper.Viper {
 				v := viper.New()
-				v.SetDefault(ShutdownTimeoutParam, time.Nanosecond)
+				v.SetDefault(ShutdownTimeoutParam, time.Second)
 
 				return v
 			}},
@@ -127,7 +166,8 @@ func TestServices(t *testing.T) {
 
 			close(start)
 
-			<-time.After(time.Millisecond * 5)
+			// wait until all services are up
+			<-grp.Ready()
 
 			for i := 0; i < count; i++ {
 				if wrk, ok := services[i].(*testWorker); ok && !services[i].(*testWorker).started.Load() {
@@ -155,11 +195,222 @@ func TestServices(t *testing.T) {
 		require.False(t, wrk.started.Load())
 
 		// error should be passed from start
-		require.EqualError(t, grp.Run(context.Background()), testError.Error())
+		err := grp.Run(context.Background())
+		require.ErrorIs(t, err, testError)
+
+		var svcErr *group.Error
+		require.ErrorAs(t, err, &svcErr)
+		require.Equal(t, wrk.Name(), svcErr.Service)
+		require.Equal(t, group.PhaseStart, svcErr.Phase)
 
 		// error should be written on stop
 		require.EqualError(t, wrk.Load(), testError.Error())
 	})
+
+	t.Run("should log panic of the service", func(t *testing.T) {
+		wrk := &testPanic{testWorker: newWorker()}
+		core, logs := observer.New(zap.ErrorLevel)
+
+		grp := newGroup(Params{
+			Group:  []Service{wrk},
+			Logger: zap.New(core),
+		})
+
+		var perr *group.PanicError
+		require.ErrorAs(t, grp.Run(context.Background()), &perr)
+		require.Equal(t, wrk.Name(), perr.Service)
+
+		entries := logs.FilterMessage("service panicked").All()
+		require.Len(t, entries, 1)
+		require.Equal(t, wrk.Name(), entries[0].ContextMap()["name"])
+		require.Equal(t, testError.Error(), entries[0].ContextMap()["panic"])
+		require.NotEmpty(t, entries[0].ContextMap()["stack"])
+	})
+
+	t.Run("should collect errors on shutdown", func(t *testing.T) {
+		wrk := &testShutdowner{testWorker: newWorker()}
+		wrk.errored = true
+
+		grp := newGroup(Params{
+			Group:  []Service{wrk},
+			Logger: zaptest.NewLogger(t),
+		})
+
+		var errs group.Errors
+		require.ErrorAs(t, grp.Run(context.Background()), &errs)
+		require.Equal(t, group.Errors{
+			{Service: wrk.Name(), Phase: group.PhaseStart, Err: testError},
+			{Service: wrk.Name(), Phase: group.PhaseStop, Err: testError},
+		}, errs)
+	})
+}
+
+func TestServicesConfigs(t *testing.T) {
+	t.Run("should read configs from viper", func(t *testing.T) {
+		v := viper.New()
+		v.Set(RestartParam+".policy", "on-failure")
+		v.Set(RestartParam+".max_restarts", 3)
+		v.Set(RestartParam+".window", "1m")
+		v.Set(ServicesParam, []map[string]interface{}{
+			{"name": "api", "after": []string{"cache"}},
+			{"name": "cache", "phase": 1, "restart": map[string]interface{}{"policy": "always"}},
+			{"name": "db", "shutdown_timeout": "30s"},
+		})
+
+		out, err := newParam(v)
+		require.NoError(t, err)
+		require.Equal(t, group.RestartConfig{
+			Policy:      group.RestartOnFailure,
+			MaxRestarts: 3,
+			Window:      time.Minute,
+		}, out.Restart)
+		require.Equal(t, []Config{
+			{Name: "api", After: []string{"cache"}},
+			{Name: "cache", Phase: 1, Restart: &group.RestartConfig{Policy: group.RestartAlways}},
+			{Name: "db", ShutdownTimeout: time.Second * 30},
+		}, out.Configs)
+	})
+
+	t.Run("should abandon service by shutdown timeout", func(t *testing.T) {
+		stuck := &testStuck{testWorker: newWorker(), release: make(chan struct{})}
+		defer close(stuck.release)
+
+		core, logs := observer.New(zap.InfoLevel)
+		grp := newGroup(Params{
+			Group:    []Service{stuck, newWorker()},
+			Logger:   zap.New(core),
+			Shutdown: time.Second * 10,
+			Configs:  []Config{{Name: stuck.Name(), ShutdownTimeout: time.Millisecond * 10}},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() { done <- grp.Run(ctx) }()
+
+		<-grp.Ready()
+		cancel()
+
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, group.ErrShutdownTimeout)
+		case <-time.After(time.Second * 5):
+			t.Fatal("service should be abandoned")
+		}
+
+		require.Equal(t, 1, logs.FilterMessage("service was not stopped in time and was abandoned").
+			FilterField(zap.String("name", stuck.Name())).Len())
+		require.Equal(t, 1, logs.FilterMessage("service stopped").Len())
+		require.Equal(t, 1, logs.FilterMessage("some services were abandoned on shutdown").Len())
+	})
+
+	t.Run("should restart service by config", func(t *testing.T) {
+		wrk := newWorker()
+		wrk.errored = true
+
+		grp := newGroup(Params{
+			Group:  []Service{wrk},
+			Logger: zaptest.NewLogger(t),
+			Configs: []Config{{Name: wrk.Name(), Restart: &group.RestartConfig{
+				Policy:      group.RestartOnFailure,
+				MinBackoff:  time.Millisecond,
+				MaxRestarts: 1,
+			}}},
+		})
+
+		require.ErrorIs(t, grp.Run(context.Background()), group.ErrRestartsExhausted)
+	})
+
+	t.Run("should report failed optional service", func(t *testing.T) {
+		failed, worker := newWorker(), newWorker()
+		failed.errored = true
+
+		monitor := NewMonitor()
+		grp := newGroup(Params{
+			Group:   []Service{failed, worker},
+			Logger:  zaptest.NewLogger(t),
+			Monitor: monitor,
+			Configs: []Config{{Name: failed.Name(), Optional: true}},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() { done <- grp.Run(ctx) }()
+
+		// worker should keep running
+		<-worker.Ready()
+		require.Eventually(t, func() bool {
+			return monitor.Check(ctx) != nil
+		}, time.Second, time.Millisecond)
+
+		require.True(t, worker.started.Load())
+		require.ErrorIs(t, monitor.Failed()[failed.Name()], testError)
+
+		// failure is cleared, when the service is running again
+		restored := newWorker()
+		restored.number = failed.number
+
+		require.NoError(t, grp.Detach(ctx, failed.Name()))
+		require.NoError(t, grp.Attach(restored))
+
+		<-restored.Ready()
+		require.Eventually(t, func() bool {
+			return monitor.Check(ctx) == nil
+		}, time.Second, time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("should fail on dependency cycle", func(t *testing.T) {
+		first, second := newWorker(), newWorker()
+
+		grp := newGroup(Params{
+			Group:  []Service{first, second},
+			Logger: zaptest.NewLogger(t),
+			Configs: []Config{
+				{Name: first.Name(), After: []string{second.Name()}},
+				{Name: second.Name(), After: []string{first.Name()}},
+			},
+		})
+
+		require.ErrorIs(t, grp.Run(context.Background()), group.ErrDependencyCycle)
+	})
+}
+
+func TestServicesAttach(t *testing.T) {
+	main, tenant := newWorker(), newWorker()
+
+	grp := newGroup(Params{
+		Group:   []Service{main},
+		Logger:  zaptest.NewLogger(t),
+		Configs: []Config{{Name: tenant.Name(), After: []string{main.Name()}}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- grp.Run(ctx) }()
+
+	<-grp.Ready()
+
+	require.ErrorIs(t, grp.Attach(nil), ErrEmptyService)
+	require.NoError(t, grp.Attach(tenant))
+
+	<-tenant.Ready()
+	require.True(t, tenant.started.Load())
+
+	// main service could not be detached, because tenant depends on it
+	require.ErrorIs(t, grp.Detach(ctx, main.Name()), group.ErrServiceHasDependents)
+
+	require.NoError(t, grp.Detach(ctx, tenant.Name()))
+	require.False(t, tenant.started.Load())
+	require.Len(t, grp.States(), 1)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.False(t, main.started.Load())
 }
 
 func TestServicesFromDI(t *testing.T) {
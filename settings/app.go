@@ -1,27 +1,51 @@
 package settings
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 	"go.uber.org/dig"
 
+	"github.com/im-kulikov/helium/internal"
 	"github.com/im-kulikov/helium/module"
 )
 
 type (
 	// Defaults is callback that allows to setup application before run.
-	// Helium calls `defaults` handler at the end in `New` method.
+	// Helium calls `defaults` handler in `New` method before conditions of modules are checked (see module.Setup).
 	Defaults interface{}
 
 	// Core configuration.
 	Core struct {
-		File         string
-		Type         string
+		File string
+		Type string
+
+		// Files contains additional config files and directories (conf.d), see ConfigFiles.
+		Files []string
+
+		// Env selects overlays of config files, e.g. `config.production.yaml`.
+		Env string
+
 		Name         string
 		Prefix       string
 		BuildTime    string
 		BuildVersion string
+
+		// Flags of command line are bound into settings (see cli.Bind), so conditions of modules could use them.
+		Flags *pflag.FlagSet
 	}
 )
 
+const (
+	// ErrUnsupportedType is raised when config type is not supported by viper.
+	ErrUnsupportedType = internal.Error("unsupported config type")
+
+	defaultType = "yaml"
+)
+
 // DIProvider wrap di into provider.
 func DIProvider(di *dig.Container) *module.Provider {
 	return &module.Provider{
@@ -36,14 +60,36 @@ func (a *Core) Provider() *module.Provider {
 	}
 }
 
-// SafeType returns config type, default config type: yaml.
-// returns yml if config type not supported.
-// nolint:goconst
+// ConfigType returns type of the config: Type or extension of the File, yaml is used when both are empty.
+// ErrUnsupportedType is returned when viper could not decode config of that type.
+func (a *Core) ConfigType() (string, error) {
+	typ := strings.ToLower(a.Type)
+	if typ == "" {
+		typ = strings.ToLower(strings.TrimPrefix(filepath.Ext(a.File), "."))
+	}
+
+	if typ == "" {
+		return defaultType, nil
+	}
+
+	for _, ext := range viper.SupportedExts {
+		if ext == typ {
+			return typ, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w %q, supported types: %s", ErrUnsupportedType, typ, strings.Join(viper.SupportedExts, ", "))
+}
+
+// SafeType returns config type (see ConfigType), default config type: yaml.
+// returns yaml if config type not supported.
+//
+// Deprecated: use ConfigType, that reports unsupported types.
 func (a *Core) SafeType() string {
-	switch a.Type {
-	case "toml", "yml", "yaml":
-		return a.Type
-	default:
-		return "yaml"
+	typ, err := a.ConfigType()
+	if err != nil {
+		return defaultType
 	}
+
+	return typ
 }
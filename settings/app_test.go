@@ -48,4 +48,33 @@ func TestApp(t *testing.T) {
 			require.Equal(t, item, cfg.SafeType())
 		}
 	})
+
+	t.Run("config type", func(t *testing.T) {
+		cases := []struct {
+			core     Core
+			expected string
+		}{
+			{core: Core{}, expected: "yaml"},
+			{core: Core{File: "config"}, expected: "yaml"},
+			{core: Core{File: "config.json"}, expected: "json"},
+			{core: Core{File: "/etc/app/config.HCL"}, expected: "hcl"},
+			{core: Core{File: "config.properties"}, expected: "properties"},
+			{core: Core{File: ".env"}, expected: "env"},
+			{core: Core{File: "config.json", Type: "dotenv"}, expected: "dotenv"},
+			{core: Core{Type: "INI"}, expected: "ini"},
+		}
+
+		for _, tt := range cases {
+			typ, err := tt.core.ConfigType()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, typ)
+		}
+
+		for _, core := range []Core{{Type: "bad"}, {File: "config.xml"}, {File: "config.yaml", Type: "xml"}} {
+			typ, err := core.ConfigType()
+			require.ErrorIs(t, err, ErrUnsupportedType)
+			require.Empty(t, typ)
+			require.Equal(t, "yaml", core.SafeType())
+		}
+	})
 }
@@ -0,0 +1,441 @@
+package settings
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/module"
+)
+
+type (
+	// Violation of the validation rule, Key is the full path of the setting, e.g. `api.read_timeout`.
+	Violation struct {
+		Key     string
+		Rule    string
+		Message string
+	}
+
+	// Violations contains all violations found by Unmarshal.
+	// It matches ErrConfig by errors.Is.
+	Violations []Violation
+
+	bindParams struct {
+		dig.In
+
+		Viper   *viper.Viper
+		Secrets *Secrets `optional:"true"`
+	}
+)
+
+const (
+	// ErrBindTarget is raised when settings are unmarshalled not into pointer to struct.
+	ErrBindTarget = internal.Error("target should be a pointer to struct")
+
+	tagName     = "mapstructure"
+	tagDefault  = "default"
+	tagValidate = "validate"
+
+	ruleRequired = "required"
+	ruleMin      = "min"
+	ruleMax      = "max"
+	ruleOneOf    = "oneof"
+	ruleUnknown  = "unknown"
+)
+
+// nolint:gochecknoglobals
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Error returns all violations as readable list.
+func (v Violations) Error() string {
+	list := make([]string, 0, len(v)+1)
+	list = append(list, fmt.Sprintf("%s, found %d violation(s):", ErrConfig, len(v)))
+
+	for i := range v {
+		list = append(list, fmt.Sprintf("  - %s: %s", v[i].Key, v[i].Message))
+	}
+
+	return strings.Join(list, "\n")
+}
+
+// Is allows to check violations by ErrConfig.
+func (v Violations) Is(target error) bool {
+	return target == ErrConfig
+}
+
+// Bind creates module, that provides *T unmarshalled from the subtree of settings by the key (see Unmarshal).
+// The same checks are registered as config validator, so they are called by `config validate` and config reload.
+// Defaults of `default` tags are registered in settings when the module is built, so they are visible
+// for other consumers of settings. Values of secrets are redacted in errors, when Secrets are provided.
+func Bind[T any](key string, opts ...dig.ProvideOption) module.Module {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	return module.Module{
+		{
+			Constructor: func(p bindParams) (*T, error) {
+				setDefaults(p.Viper, key, typ)
+
+				out := new(T)
+				if err := Unmarshal(p.Viper, key, out); err != nil {
+					return nil, redactError(err, p.Secrets)
+				}
+
+				return out, nil
+			},
+			Options: opts,
+		},
+		{
+			Constructor: func(p bindParams) ValidatorResult {
+				// validators could be called without the config, e.g. by `config validate`
+				setDefaults(p.Viper, key, typ)
+
+				return ValidatorResult{Validator: func(v *viper.Viper) error {
+					return redactError(Unmarshal(v, key, new(T)), p.Secrets)
+				}}
+			},
+		},
+	}
+}
+
+// Unmarshal decodes the subtree of settings by the key into the struct by mapstructure tags:
+// - `default:"value"` tag sets default value of the setting, settings are not changed, see Bind;
+// - `validate:"required,min=1,max=10,oneof=a b c"` tag checks the value, min and max check length
+// of strings, slices and maps, durations are passed as `min=1s`; rules except required are skipped for missing keys,
+// explicitly set zero values (e.g. `0` or `""`) are checked;
+// - unknown keys of the subtree are reported, so typos are not ignored.
+// All violations are returned as Violations with full keys of settings.
+func Unmarshal(v *viper.Viper, key string, out interface{}) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrBindTarget, out)
+	}
+
+	// settings are only read, so they could be checked concurrently, e.g. by config reload
+	all := v.AllSettings()
+	defaults(key, target.Elem().Type(), func(path, value string) { setMissing(all, path, value) })
+
+	var meta mapstructure.Metadata
+
+	// settings are taken key by key, so nested defaults and environment variables are merged with the config file
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           out,
+		Metadata:         &meta,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = decoder.Decode(subtree(all, key)); err != nil {
+		return fmt.Errorf("%w %q: %v", ErrConfig, key, err)
+	}
+
+	var violations Violations
+
+	sort.Strings(meta.Unused)
+
+	for _, unused := range meta.Unused {
+		violations = append(violations, Violation{Key: join(key, unused), Rule: ruleUnknown, Message: "unknown key"})
+	}
+
+	violations = append(violations, validate(all, key, target.Elem())...)
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return violations
+}
+
+// setDefaults sets defaults of settings by `default` tags.
+func setDefaults(v *viper.Viper, key string, typ reflect.Type) {
+	defaults(key, typ, func(path, value string) { v.SetDefault(path, value) })
+}
+
+// defaults calls fn for every `default` tag of the struct with the full key of the setting.
+func defaults(key string, typ reflect.Type, fn func(path, value string)) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name, squash, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+
+		path := join(key, name)
+		if squash {
+			path = key
+		}
+
+		if value, ok := field.Tag.Lookup(tagDefault); ok {
+			fn(path, value)
+
+			continue
+		}
+
+		if nested := structType(field.Type); nested != nil {
+			defaults(path, nested, fn)
+		}
+	}
+}
+
+// setMissing sets the value into nested settings by the key, when it was not set.
+func setMissing(all map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(strings.ToLower(key), ".")
+
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := all[part]
+		if !ok {
+			nested = make(map[string]interface{})
+			all[part] = nested
+		}
+
+		if all, ok = nested.(map[string]interface{}); !ok {
+			return
+		}
+	}
+
+	if _, ok := all[parts[len(parts)-1]]; !ok {
+		all[parts[len(parts)-1]] = value
+	}
+}
+
+// validate checks values of the struct by `validate` tags.
+func validate(all map[string]interface{}, key string, val reflect.Value) Violations {
+	var violations Violations
+
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+
+		name, squash, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+
+		path := join(key, name)
+		if squash {
+			path = key
+		}
+
+		value := val.Field(i)
+
+		if rules, ok := field.Tag.Lookup(tagValidate); ok {
+			violations = append(violations, check(path, value, rules, value.IsZero() && !isSet(all, path))...)
+		}
+
+		if value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+
+		if value.Kind() == reflect.Struct && structType(value.Type()) != nil {
+			violations = append(violations, validate(all, path, value)...)
+		}
+	}
+
+	return violations
+}
+
+// check applies rules to the value, rules except required are skipped for missing values.
+func check(key string, value reflect.Value, rules string, missing bool) Violations {
+	var violations Violations
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param := rule, ""
+		if idx := strings.Index(rule, "="); idx >= 0 {
+			name, param = rule[:idx], rule[idx+1:]
+		}
+
+		var message string
+
+		switch name = strings.TrimSpace(name); {
+		case name == ruleRequired:
+			if value.IsZero() {
+				message = "is required"
+			}
+		case missing:
+			// other rules are checked only for passed values
+		case name == ruleMin, name == ruleMax:
+			message = compare(name, value, param)
+		case name == ruleOneOf:
+			if options := strings.Fields(param); !contains(options, fmt.Sprint(value.Interface())) {
+				message = fmt.Sprintf("must be one of [%s], got %v", strings.Join(options, ", "), value.Interface())
+			}
+		default:
+			message = fmt.Sprintf("unknown validation rule %q", name)
+		}
+
+		if message != "" {
+			violations = append(violations, Violation{Key: key, Rule: name, Message: message})
+		}
+	}
+
+	return violations
+}
+
+// compare checks min or max rule, length is compared for strings, slices and maps.
+func compare(rule string, value reflect.Value, param string) string {
+	var (
+		actual, limit float64
+		err           error
+		subject       = "value"
+	)
+
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		subject = "length"
+		actual = float64(value.Len())
+		limit, err = strconv.ParseFloat(param, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+
+		if value.Type() == durationType {
+			var limitDuration time.Duration
+			limitDuration, err = time.ParseDuration(param)
+			limit = float64(limitDuration)
+		} else {
+			limit, err = strconv.ParseFloat(param, 64)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+		limit, err = strconv.ParseFloat(param, 64)
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+		limit, err = strconv.ParseFloat(param, 64)
+	default:
+		return fmt.Sprintf("rule %q is not supported for %s", rule, value.Type())
+	}
+
+	switch {
+	case err != nil:
+		return fmt.Sprintf("invalid parameter of rule %q: %v", rule, err)
+	case rule == ruleMin && actual < limit:
+		return fmt.Sprintf("%s must be at least %s, got %v", subject, param, lengthOrValue(subject, value))
+	case rule == ruleMax && actual > limit:
+		return fmt.Sprintf("%s must be at most %s, got %v", subject, param, lengthOrValue(subject, value))
+	default:
+		return ""
+	}
+}
+
+func lengthOrValue(subject string, value reflect.Value) interface{} {
+	if subject == "length" {
+		return value.Len()
+	}
+
+	return value.Interface()
+}
+
+// fieldName returns name of the setting by mapstructure tag, squash flag and false for skipped fields.
+func fieldName(field reflect.StructField) (string, bool, bool) {
+	if field.PkgPath != "" {
+		// unexported field
+		return "", false, false
+	}
+
+	tag := strings.Split(field.Tag.Get(tagName), ",")
+	if tag[0] == "-" {
+		return "", false, false
+	}
+
+	for _, option := range tag[1:] {
+		if option == "squash" {
+			return "", true, true
+		}
+	}
+
+	if tag[0] != "" {
+		return strings.ToLower(tag[0]), false, true
+	}
+
+	return strings.ToLower(field.Name), false, true
+}
+
+// structType returns type of nested settings, durations and other types without exported fields are skipped.
+func structType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath == "" {
+			return typ
+		}
+	}
+
+	return nil
+}
+
+// subtree returns nested settings by the key, empty key means all settings.
+func subtree(all map[string]interface{}, key string) interface{} {
+	var result interface{} = all
+
+	if key == "" {
+		return result
+	}
+
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		nested, ok := result.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		result = nested[part]
+	}
+
+	return result
+}
+
+// isSet checks that nested settings contain the key, defaults are merged into settings by Unmarshal.
+func isSet(all map[string]interface{}, key string) bool {
+	parts := strings.Split(strings.ToLower(key), ".")
+
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := all[part].(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		all = nested
+	}
+
+	_, ok := all[parts[len(parts)-1]]
+
+	return ok
+}
+
+func join(key, name string) string {
+	switch {
+	case key == "":
+		return name
+	case name == "":
+		return key
+	default:
+		return key + "." + name
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,188 @@
+package settings
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/module"
+)
+
+type (
+	// BindServer is exported, because unexported embedded structs could not be decoded.
+	BindServer struct {
+		Name    string        `mapstructure:"name" default:"api"`
+		Address string        `mapstructure:"address" validate:"required"`
+		Timeout time.Duration `mapstructure:"read_timeout" default:"10s" validate:"min=1s,max=1m"`
+	}
+
+	bindConfig struct {
+		BindServer `mapstructure:",squash"`
+
+		Format  string         `mapstructure:"format" default:"json" validate:"oneof=json console"`
+		Workers int            `validate:"min=1,max=8"`
+		Tags    []string       `mapstructure:"tags" validate:"max=2"`
+		Limits  *bindLimits    `mapstructure:"limits"`
+		Skipped *viper.Viper   `mapstructure:"-" validate:"required"`
+		Labels  map[string]int `mapstructure:"labels"`
+
+		internal string
+	}
+
+	bindLimits struct {
+		Rate  float64 `mapstructure:"rate" validate:"required,max=1"`
+		Burst uint    `mapstructure:"burst" default:"10" validate:"min=5"`
+	}
+)
+
+func TestBind(t *testing.T) {
+	read := func(t *testing.T, content string) *viper.Viper {
+		v := viper.New()
+		v.SetConfigType("yaml")
+		require.NoError(t, v.ReadConfig(strings.NewReader(content)))
+
+		return v
+	}
+
+	t.Run("should apply defaults", func(t *testing.T) {
+		v := read(t, "api:\n  address: :8080\n  workers: 2\n  limits:\n    rate: 0.5\n")
+
+		var cfg bindConfig
+		require.NoError(t, Unmarshal(v, "api", &cfg))
+		require.Equal(t, "api", cfg.Name)
+		require.Equal(t, ":8080", cfg.Address)
+		require.Equal(t, 10*time.Second, cfg.Timeout)
+		require.Equal(t, "json", cfg.Format)
+		require.Equal(t, 2, cfg.Workers)
+		require.Equal(t, &bindLimits{Rate: 0.5, Burst: 10}, cfg.Limits)
+
+		// settings are not changed, defaults are registered by Bind
+		require.False(t, v.IsSet("api.read_timeout"))
+		require.False(t, v.IsSet("api.limits.burst"))
+	})
+
+	t.Run("should report every violation with full key", func(t *testing.T) {
+		v := read(t, `
+api:
+  read_timeout: 2m
+  format: text
+  workers: 10
+  tags: [a, b, c]
+  adress: typo
+  limits:
+    rate: 2
+    burst: 1
+    unknown: true
+`)
+
+		err := Unmarshal(v, "api", new(bindConfig))
+		require.ErrorIs(t, err, ErrConfig)
+
+		var violations Violations
+		require.ErrorAs(t, err, &violations)
+		require.Equal(t, Violations{
+			{Key: "api.adress", Rule: ruleUnknown, Message: "unknown key"},
+			{Key: "api.limits.unknown", Rule: ruleUnknown, Message: "unknown key"},
+			{Key: "api.address", Rule: ruleRequired, Message: "is required"},
+			{Key: "api.read_timeout", Rule: ruleMax, Message: "value must be at most 1m, got 2m0s"},
+			{Key: "api.format", Rule: ruleOneOf, Message: "must be one of [json, console], got text"},
+			{Key: "api.workers", Rule: ruleMax, Message: "value must be at most 8, got 10"},
+			{Key: "api.tags", Rule: ruleMax, Message: "length must be at most 2, got 3"},
+			{Key: "api.limits.rate", Rule: ruleMax, Message: "value must be at most 1, got 2"},
+			{Key: "api.limits.burst", Rule: ruleMin, Message: "value must be at least 5, got 1"},
+		}, violations)
+
+		require.Contains(t, err.Error(), "could not read config, found 9 violation(s):\n  - api.adress: unknown key\n")
+	})
+
+	t.Run("should check explicitly set zero values", func(t *testing.T) {
+		v := read(t, "api:\n  address: :8080\n  format: \"\"\n  workers: 0\n  read_timeout: 0s\n  limits:\n    rate: 0.5\n")
+
+		var violations Violations
+		require.ErrorAs(t, Unmarshal(v, "api", new(bindConfig)), &violations)
+		require.Equal(t, Violations{
+			{Key: "api.read_timeout", Rule: ruleMin, Message: "value must be at least 1s, got 0s"},
+			{Key: "api.format", Rule: ruleOneOf, Message: "must be one of [json, console], got "},
+			{Key: "api.workers", Rule: ruleMin, Message: "value must be at least 1, got 0"},
+		}, violations)
+
+		// missing keys are not checked
+		require.NoError(t, Unmarshal(read(t, "api:\n  address: :8080\n  limits:\n    rate: 0.5\n"), "api", new(bindConfig)))
+	})
+
+	t.Run("should report invalid rules and values", func(t *testing.T) {
+		var cfg struct {
+			Unknown string        `validate:"email"`
+			Param   int           `validate:"min=one"`
+			Struct  bindLimits    `validate:"min=1"`
+			Timeout time.Duration `mapstructure:"timeout"`
+		}
+
+		err := Unmarshal(read(t, "unknown: a\nparam: 1\nstruct:\n  rate: 1\n"), "", &cfg)
+
+		var violations Violations
+		require.ErrorAs(t, err, &violations)
+		require.Equal(t, Violations{
+			{Key: "unknown", Rule: "email", Message: `unknown validation rule "email"`},
+			{Key: "param", Rule: ruleMin, Message: `invalid parameter of rule "min": strconv.ParseFloat: parsing "one": invalid syntax`},
+			{Key: "struct", Rule: ruleMin, Message: `rule "min" is not supported for settings.bindLimits`},
+		}, violations)
+
+		err = Unmarshal(read(t, "timeout: forever\n"), "", &cfg)
+		require.ErrorIs(t, err, ErrConfig)
+		require.Contains(t, err.Error(), "timeout")
+	})
+
+	t.Run("should fail on invalid target", func(t *testing.T) {
+		var cfg bindConfig
+
+		require.ErrorIs(t, Unmarshal(viper.New(), "api", cfg), ErrBindTarget)
+		require.ErrorIs(t, Unmarshal(viper.New(), "api", (*bindConfig)(nil)), ErrBindTarget)
+		require.ErrorIs(t, Unmarshal(viper.New(), "api", new(int)), ErrBindTarget)
+	})
+
+	t.Run("should provide config and validator", func(t *testing.T) {
+		v := read(t, "api:\n  address: :8080\n")
+
+		di := dig.New()
+		require.NoError(t, module.Provide(di, module.Module{
+			{Constructor: func() *viper.Viper { return v }},
+		}.Append(Bind[BindServer]("api"))))
+
+		require.NoError(t, di.Invoke(func(cfg *BindServer, p struct {
+			dig.In
+
+			Validators []Validator `group:"config_validators"`
+		},
+		) {
+			require.Equal(t, &BindServer{Name: "api", Address: ":8080", Timeout: 10 * time.Second}, cfg)
+
+			// defaults are visible for other consumers of settings
+			require.Equal(t, "10s", v.GetString("api.read_timeout"))
+
+			// defaults are not registered in passed settings, so they could be checked concurrently
+			next := read(t, "api:\n  address: :8081\n")
+			require.NoError(t, p.Validators[0](next))
+			require.False(t, next.IsSet("api.read_timeout"))
+			require.Len(t, p.Validators, 1)
+			require.NoError(t, p.Validators[0](v))
+
+			invalid := read(t, "api:\n  address: :8080\n  read_timeout: 1ms\n")
+			require.ErrorIs(t, p.Validators[0](invalid), ErrConfig, "passed settings should be checked")
+			require.NoError(t, p.Validators[0](v))
+		}))
+
+		di = dig.New()
+		require.NoError(t, module.Provide(di, module.Module{
+			{Constructor: viper.New},
+		}.Append(Bind[BindServer]("api"))))
+
+		err := di.Invoke(func(*BindServer) {})
+		require.ErrorIs(t, err, ErrConfig)
+		require.Contains(t, err.Error(), "api.address: is required")
+	})
+}
@@ -0,0 +1,117 @@
+package settings
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/module"
+)
+
+// BuildInfo describes the build of the application.
+// Values injected by ldflags (see Core) take precedence over values from debug.ReadBuildInfo.
+type BuildInfo struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Time      string `json:"time,omitempty"`
+	VCSTime   string `json:"vcs_time,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Dirty     bool   `json:"dirty"`
+	GoVersion string `json:"go_version"`
+}
+
+const (
+	develVersion = "(devel)"
+	unknown      = "dev"
+
+	revisionLength = 12
+
+	vcsRevision = "vcs.revision"
+	vcsTime     = "vcs.time"
+	vcsModified = "vcs.modified"
+)
+
+// NewBuildInfo creates BuildInfo of the application and exports it as `helium_build_info` gauge.
+// Empty version and build time are filled from debug.ReadBuildInfo: module version or VCS revision and VCS time.
+func NewBuildInfo(app *Core) *BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+
+	result := newBuildInfo(app, info)
+
+	gauge := buildInfoGauge()
+	gauge.Reset()
+	gauge.WithLabelValues(result.Name, result.Version, result.Revision,
+		strconv.FormatBool(result.Dirty), result.GoVersion).Set(1)
+
+	return result
+}
+
+// buildInfoGauge returns `helium_build_info` gauge, it is registered only once.
+func buildInfoGauge() *prometheus.GaugeVec {
+	return internal.Register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "helium",
+		Name:      "build_info",
+		Help:      "Build information of the application, value is always 1.",
+	}, []string{"name", "version", "revision", "dirty", "go_version"}))
+}
+
+// Provider wraps build info into provider.
+func (b *BuildInfo) Provider() *module.Provider {
+	return &module.Provider{
+		Constructor: func() *BuildInfo { return b },
+	}
+}
+
+func newBuildInfo(app *Core, info *debug.BuildInfo) *BuildInfo {
+	result := &BuildInfo{
+		Name:      app.Name,
+		Version:   app.BuildVersion,
+		Time:      app.BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if info != nil {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case vcsRevision:
+				result.Revision = setting.Value
+			case vcsTime:
+				result.VCSTime = setting.Value
+			case vcsModified:
+				result.Dirty = setting.Value == "true"
+			}
+		}
+
+		if info.GoVersion != "" {
+			result.GoVersion = info.GoVersion
+		}
+
+		if result.Version == "" && info.Main.Version != develVersion {
+			result.Version = info.Main.Version
+		}
+	}
+
+	if result.Time == "" {
+		result.Time = result.VCSTime
+	}
+
+	if result.Version == "" && result.Revision != "" {
+		result.Version = result.Revision
+		if len(result.Version) > revisionLength {
+			result.Version = result.Version[:revisionLength]
+		}
+
+		if result.Dirty {
+			result.Version += "-dirty"
+		}
+	}
+
+	if result.Version == "" {
+		result.Version = unknown
+	}
+
+	return result
+}
@@ -0,0 +1,69 @@
+package settings
+
+import (
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfo(t *testing.T) {
+	vcs := &debug.BuildInfo{
+		GoVersion: "go1.19",
+		Main:      debug.Module{Version: develVersion},
+		Settings: []debug.BuildSetting{
+			{Key: vcsRevision, Value: "0123456789abcdef"},
+			{Key: vcsTime, Value: "2022-01-02T03:04:05Z"},
+			{Key: vcsModified, Value: "true"},
+		},
+	}
+
+	t.Run("should prefer injected values", func(t *testing.T) {
+		info := newBuildInfo(&Core{Name: "app", BuildVersion: "v1.0.0", BuildTime: "now"}, vcs)
+		require.Equal(t, &BuildInfo{
+			Name:      "app",
+			Version:   "v1.0.0",
+			Time:      "now",
+			VCSTime:   "2022-01-02T03:04:05Z",
+			Revision:  "0123456789abcdef",
+			Dirty:     true,
+			GoVersion: "go1.19",
+		}, info)
+	})
+
+	t.Run("should fall back to vcs revision", func(t *testing.T) {
+		info := newBuildInfo(&Core{Name: "app"}, vcs)
+		require.Equal(t, "0123456789ab-dirty", info.Version)
+		require.Equal(t, "2022-01-02T03:04:05Z", info.Time)
+		require.Equal(t, "2022-01-02T03:04:05Z", info.VCSTime)
+	})
+
+	t.Run("should fall back to module version", func(t *testing.T) {
+		info := newBuildInfo(&Core{}, &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}})
+		require.Equal(t, "v1.2.3", info.Version)
+		require.Empty(t, info.Revision)
+		require.False(t, info.Dirty)
+		require.NotEmpty(t, info.GoVersion)
+	})
+
+	t.Run("should be dev without build info", func(t *testing.T) {
+		require.Equal(t, unknown, newBuildInfo(&Core{}, nil).Version)
+		require.Equal(t, unknown, newBuildInfo(&Core{}, &debug.BuildInfo{Main: debug.Module{Version: develVersion}}).Version)
+	})
+
+	t.Run("should export gauge", func(t *testing.T) {
+		info := NewBuildInfo(&Core{Name: "gauge", BuildVersion: "v1.0.0"})
+		require.Equal(t, info, info.Provider().Constructor.(func() *BuildInfo)())
+
+		expected := `
+# HELP helium_build_info Build information of the application, value is always 1.
+# TYPE helium_build_info gauge
+helium_build_info{dirty="` + strconv.FormatBool(info.Dirty) + `",go_version="` + info.GoVersion +
+			`",name="gauge",revision="` + info.Revision + `",version="v1.0.0"} 1
+`
+		require.NoError(t, testutil.CollectAndCompare(buildInfoGauge(), strings.NewReader(expected)))
+	})
+}
@@ -1,18 +1,34 @@
 package settings
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/spf13/viper"
+	"go.uber.org/dig"
 
+	"github.com/im-kulikov/helium/cli"
+	"github.com/im-kulikov/helium/internal"
 	"github.com/im-kulikov/helium/module"
 )
 
+// settingsParams allows to pass custom secret providers.
+type settingsParams struct {
+	dig.In
+
+	App       *Core
+	Providers []SecretProvider `group:"secret_providers"`
+}
+
+// ErrConfig is raised when config file could not be read or parsed.
+const ErrConfig = internal.Error("could not read config")
+
 // Module of config things.
+// Watch mode of config is enabled by WatchKey (see Reloader).
 // nolint:gochecknoglobals
-var Module = module.Module{
-	{Constructor: New},
-}
+var Module = module.Named(module.Info{Name: "settings.Module"}, module.Module{
+	{Constructor: newSettings},
+}, module.When(WatchKey, module.New(newReloader)))
 
 // nolint:gochecknoglobals
 var global = viper.New()
@@ -20,21 +36,70 @@ var global = viper.New()
 // Viper returns global Viper instance.
 func Viper() *viper.Viper { return global }
 
-// New init viper settings.
-func New(app *Core) (*viper.Viper, error) {
-	v := viper.New()
+// New init viper settings, config files are merged in order (see Core.ConfigFiles),
+// references to secrets are resolved by passed and builtin providers (see Secrets).
+func New(app *Core, providers ...SecretProvider) (*viper.Viper, error) {
+	v, _, err := load(app, NewSecrets(providers...))
+
+	return v, err
+}
+
+// newSettings provides settings, sources of their keys and secrets.
+func newSettings(p settingsParams) (*viper.Viper, *Sources, *Secrets, error) {
+	secrets := NewSecrets(p.Providers...)
+
+	v, sources, err := load(p.App, secrets)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return v, sources, secrets, nil
+}
+
+func load(app *Core, secrets *Secrets) (*viper.Viper, *Sources, error) {
+	v, err := newViper(app)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	global = v
+
+	layers, err := readLayers(app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = mergeLayers(v, layers); err != nil {
+		return nil, nil, err
+	}
+
+	if err = secrets.resolve(v, false); err != nil {
+		return nil, nil, err
+	}
+
+	sources := &Sources{prefix: app.Prefix}
+	if err = sources.update(layers); err != nil {
+		return nil, nil, err
+	}
+
+	return v, sources, nil
+}
+
+// newViper creates settings without config: environment variables and flags are bound to them.
+func newViper(app *Core) (*viper.Viper, error) {
+	v := viper.New()
 	v.SetEnvPrefix(app.Prefix)
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	if app.Flags != nil {
+		if err := cli.Bind(v, app.Flags); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConfig, err)
+		}
+	}
+
 	if len(app.File) > 0 {
-		v.SetConfigType(app.SafeType())
 		v.SetConfigFile(app.File)
-
-		if err := v.ReadInConfig(); err != nil {
-			return nil, err
-		}
 	}
 
 	return v, nil
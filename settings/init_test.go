@@ -2,6 +2,7 @@ package settings
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -30,12 +31,43 @@ func TestInit(t *testing.T) {
 		require.IsType(t, viper.New(), v)
 	})
 
+	t.Run("should read all supported formats", func(t *testing.T) {
+		files := map[string]struct{ content, key string }{
+			"config.yml":        {content: "app:\n  port: 8080\n", key: "app.port"},
+			"config.json":       {content: `{"app": {"port": 8080}}`, key: "app.port"},
+			"config.toml":       {content: "[app]\nport = 8080\n", key: "app.port"},
+			"config.hcl":        {content: "port = 8080\n", key: "port"},
+			"config.ini":        {content: "[app]\nport = 8080\n", key: "app.port"},
+			"config.properties": {content: "app.port = 8080\n", key: "app.port"},
+			"config.env":        {content: "APP_PORT=8080\n", key: "app_port"},
+		}
+
+		for name, tt := range files {
+			file := filepath.Join(t.TempDir(), name)
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0o600))
+
+			v, err := New(&Core{File: file})
+			require.NoError(t, err, name)
+			require.Equal(t, 8080, v.GetInt(tt.key), name)
+		}
+	})
+
+	t.Run("should fail on unsupported type", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "config.xml")
+		require.NoError(t, os.WriteFile(file, []byte("<app/>"), 0o600))
+
+		v, err := New(&Core{File: file})
+		require.ErrorIs(t, err, ErrConfig)
+		require.Contains(t, err.Error(), `unsupported config type "xml"`)
+		require.Nil(t, v)
+	})
+
 	t.Run("should fail", func(t *testing.T) {
 		cfg := &Core{}
 
 		cfg.File = "unknown file"
 		v, err := New(cfg)
-		require.Error(t, err)
+		require.ErrorIs(t, err, ErrConfig)
 		require.Nil(t, v)
 	})
 }
@@ -0,0 +1,263 @@
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+type (
+	// Sources allows to find out which layer of config supplied the key (see Core.ConfigFiles).
+	Sources struct {
+		mu sync.RWMutex
+
+		prefix string
+		files  []string
+		keys   map[string]string
+	}
+
+	// layer is the config file with its content.
+	layer struct {
+		file string
+		typ  string
+		data []byte
+	}
+)
+
+const sourceEnv = "env "
+
+// ConfigFiles returns config files in order of merging, every next file overrides keys of previous ones:
+// - File and Files, directories (conf.d) are expanded to config files sorted by name;
+// - every file is followed by its overlay for environment (Env), e.g. `config.production.yaml` for `config.yaml`.
+// Type is used only for File, types of other files are detected by extension.
+func (a *Core) ConfigFiles() ([]string, error) {
+	var (
+		result []string
+		seen   = make(map[string]struct{})
+	)
+
+	add := func(file string) {
+		if _, ok := seen[file]; ok {
+			return
+		}
+
+		seen[file] = struct{}{}
+		result = append(result, file)
+
+		if overlay := overlayFile(file, a.Env); overlay != "" && isFile(overlay) {
+			seen[overlay] = struct{}{}
+			result = append(result, overlay)
+		}
+	}
+
+	if a.File != "" {
+		add(a.File)
+	}
+
+	for _, path := range a.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrConfig, path, err)
+		}
+
+		if !info.IsDir() {
+			add(path)
+
+			continue
+		}
+
+		files, err := directoryFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrConfig, path, err)
+		}
+
+		for _, file := range files {
+			add(file)
+		}
+	}
+
+	return result, nil
+}
+
+// Source returns the layer that supplied the key: `env <NAME>` when environment variable is set,
+// otherwise config file. Empty string is returned for defaults and unknown keys.
+func (s *Sources) Source(key string) string {
+	key = strings.ToLower(key)
+
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if s.prefix != "" {
+		name = strings.ToUpper(s.prefix) + "_" + name
+	}
+
+	if _, ok := os.LookupEnv(name); ok {
+		return sourceEnv + name
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.keys[key]
+}
+
+// Files returns config files in order of merging.
+func (s *Sources) Files() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.files...)
+}
+
+// update collects keys of every layer, the last layer that contains the key supplied it.
+func (s *Sources) update(layers []layer) error {
+	keys, err := layerKeys(layers)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(layers))
+	for _, l := range layers {
+		files = append(files, l.file)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys, s.files = keys, files
+
+	return nil
+}
+
+// layerKeys returns keys of settings from layers with files, the last layer that contains the key supplied it.
+func layerKeys(layers []layer) (map[string]string, error) {
+	keys := make(map[string]string)
+
+	for _, l := range layers {
+		v := viper.New()
+		v.SetConfigType(l.typ)
+
+		if err := v.ReadConfig(bytes.NewReader(l.data)); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrConfig, l.file, err)
+		}
+
+		for _, key := range v.AllKeys() {
+			keys[key] = l.file
+		}
+	}
+
+	return keys, nil
+}
+
+// readLayers reads config files (see Core.ConfigFiles).
+func readLayers(app *Core) ([]layer, error) {
+	files, err := app.ConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]layer, 0, len(files))
+
+	for _, file := range files {
+		core := &Core{File: file}
+		if file == app.File {
+			core.Type = app.Type
+		}
+
+		item := layer{file: file}
+		if item.typ, err = core.ConfigType(); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrConfig, file, err)
+		}
+
+		if item.data, err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrConfig, file, err)
+		}
+
+		layers = append(layers, item)
+	}
+
+	return layers, nil
+}
+
+// mergeLayers replaces config of settings by layers, merged in order (the same as viper.MergeInConfig).
+func mergeLayers(v *viper.Viper, layers []layer) error {
+	for i, l := range layers {
+		v.SetConfigType(l.typ)
+
+		read := v.MergeConfig
+		if i == 0 {
+			read = v.ReadConfig
+		}
+
+		if err := read(bytes.NewReader(l.data)); err != nil {
+			return fmt.Errorf("%w %q: %v", ErrConfig, l.file, err)
+		}
+	}
+
+	return nil
+}
+
+// overlayFile returns overlay of the file for environment, e.g. `config.production.yaml`.
+func overlayFile(file, env string) string {
+	if env == "" {
+		return ""
+	}
+
+	ext := filepath.Ext(file)
+
+	return strings.TrimSuffix(file, ext) + "." + env + ext
+}
+
+// directoryFiles returns config files of the directory sorted by name.
+// Hidden files and overlays of other files (`name.<env>.ext`) are skipped, overlays are added by ConfigFiles.
+func directoryFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = struct{}{}
+	}
+
+	var result []string
+
+	for _, entry := range entries {
+		name := entry.Name()
+		file := filepath.Join(dir, name)
+
+		if strings.HasPrefix(name, ".") || !isFile(file) {
+			continue
+		}
+
+		if _, err = (&Core{File: name}).ConfigType(); err != nil || filepath.Ext(name) == "" {
+			continue
+		}
+
+		// `name.<env>.ext` is overlay, when `name.ext` exists
+		ext := filepath.Ext(name)
+		if base := strings.TrimSuffix(name, ext); filepath.Ext(base) != "" {
+			if _, ok := names[strings.TrimSuffix(base, filepath.Ext(base))+ext]; ok {
+				continue
+			}
+		}
+
+		result = append(result, file)
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// isFile returns true for regular files, symlinks are followed (config maps of k8s).
+func isFile(file string) bool {
+	info, err := os.Stat(file)
+
+	return err == nil && info.Mode().IsRegular()
+}
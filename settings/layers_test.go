@@ -0,0 +1,107 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/module"
+)
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+	require.NoError(t, os.MkdirAll(filepath.Join(confd, "nested"), 0o700))
+
+	files := map[string]string{
+		"base.yaml":                         "app:\n  name: base\n  port: 80\nlog: info\n",
+		"base.production.yaml":              "app:\n  port: 8080\n",
+		"conf.d/10-db.yaml":                 "db:\n  host: localhost\n  user: app\n",
+		"conf.d/20-secrets.json":            `{"db": {"password": "secret"}}`,
+		"conf.d/20-secrets.production.json": `{"db": {"password": "production"}}`,
+		"conf.d/20-secrets.staging.json":    `{"db": {"password": "staging"}}`,
+		"conf.d/.hidden.yaml":               "db:\n  host: hidden\n",
+		"conf.d/README.md":                  "# not a config",
+		"conf.d/nested/30-skip.yaml":        "db:\n  host: nested\n",
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	base := filepath.Join(dir, "base.yaml")
+
+	t.Run("should return files in order of merging", func(t *testing.T) {
+		list, err := (&Core{File: base, Files: []string{confd}}).ConfigFiles()
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			base,
+			filepath.Join(confd, "10-db.yaml"),
+			filepath.Join(confd, "20-secrets.json"),
+		}, list)
+
+		list, err = (&Core{File: base, Files: []string{confd, base}, Env: "production"}).ConfigFiles()
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			base,
+			filepath.Join(dir, "base.production.yaml"),
+			filepath.Join(confd, "10-db.yaml"),
+			filepath.Join(confd, "20-secrets.json"),
+			filepath.Join(confd, "20-secrets.production.json"),
+		}, list)
+
+		list, err = (&Core{Env: "production"}).ConfigFiles()
+		require.NoError(t, err)
+		require.Empty(t, list)
+	})
+
+	t.Run("should fail on unknown path", func(t *testing.T) {
+		_, err := (&Core{Files: []string{filepath.Join(dir, "unknown")}}).ConfigFiles()
+		require.ErrorIs(t, err, ErrConfig)
+
+		_, err = New(&Core{File: base, Files: []string{filepath.Join(dir, "unknown")}})
+		require.ErrorIs(t, err, ErrConfig)
+	})
+
+	t.Run("should merge layers and report sources", func(t *testing.T) {
+		require.NoError(t, os.Setenv("LAYERS_LOG", "debug"))
+		defer func() { require.NoError(t, os.Unsetenv("LAYERS_LOG")) }()
+
+		app := &Core{File: base, Files: []string{confd}, Env: "production", Prefix: "layers"}
+
+		v, sources, _, err := newSettings(settingsParams{App: app})
+		require.NoError(t, err)
+		require.Equal(t, "base", v.GetString("app.name"))
+		require.Equal(t, 8080, v.GetInt("app.port"))
+		require.Equal(t, "localhost", v.GetString("db.host"))
+		require.Equal(t, "production", v.GetString("db.password"))
+		require.Equal(t, "debug", v.GetString("log"))
+		require.Equal(t, base, v.ConfigFileUsed())
+
+		require.Equal(t, base, sources.Source("app.name"))
+		require.Equal(t, filepath.Join(dir, "base.production.yaml"), sources.Source("APP.PORT"))
+		require.Equal(t, filepath.Join(confd, "10-db.yaml"), sources.Source("db.user"))
+		require.Equal(t, filepath.Join(confd, "20-secrets.production.json"), sources.Source("db.password"))
+		require.Equal(t, "env LAYERS_LOG", sources.Source("log"))
+		require.Empty(t, sources.Source("unknown"))
+
+		files, err := app.ConfigFiles()
+		require.NoError(t, err)
+		require.Equal(t, files, sources.Files())
+	})
+
+	t.Run("should provide sources", func(t *testing.T) {
+		di := dig.New()
+		require.NoError(t, module.Provide(di, module.Module{
+			{Constructor: func() *Core { return &Core{File: base} }},
+		}.Append(Module)))
+
+		require.NoError(t, di.Invoke(func(sources *Sources) {
+			require.Equal(t, []string{base}, sources.Files())
+			require.Equal(t, base, sources.Source("app.port"))
+		}))
+	})
+}
@@ -0,0 +1,488 @@
+package settings
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/atomic"
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/service"
+)
+
+type (
+	// Change of the single key of settings.
+	Change struct {
+		Key string
+		Old interface{}
+		New interface{}
+	}
+
+	// Event is published to subscribers when config was reloaded and some keys were changed.
+	// Settings contains the new config (see Reloader.Settings), so subscribers could take typed values from it.
+	Event struct {
+		Changes  []Change
+		Settings *viper.Viper
+	}
+
+	// Subscriber applies new values of settings, for example, changes logger level.
+	// Subscribers should be provided into DI by `group:"config_subscribers"`
+	// or could be added by Reloader.Subscribe. Subscribers are called without lock,
+	// so they could subscribe or reload settings.
+	Subscriber func(Event)
+
+	// SubscriberResult allows to provide Subscriber into DI.
+	SubscriberResult struct {
+		dig.Out
+
+		Subscriber Subscriber `group:"config_subscribers"`
+	}
+
+	// Reloader reloads config files when they were changed or SIGHUP was received.
+	// New config is loaded into new settings and checked by config validators (see Validator),
+	// invalid config is rejected and the old one is kept in place. Settings provided into DI
+	// are not changed by reloads, so they could be read concurrently, the new ones are passed
+	// to subscribers and returned by Settings.
+	Reloader struct {
+		mu sync.Mutex
+
+		app     *Core
+		base    []layer
+		layers  []layer
+		origin  *viper.Viper
+		current *atomic.Pointer[viper.Viper]
+		sources *Sources
+		secrets *Secrets
+		logger  *zap.Logger
+
+		validators  []Validator
+		subscribers []Subscriber
+
+		once sync.Once
+		done chan struct{}
+	}
+
+	reloaderParams struct {
+		dig.In
+
+		App         *Core
+		Viper       *viper.Viper
+		Sources     *Sources `optional:"true"`
+		Secrets     *Secrets `optional:"true"`
+		Logger      *zap.Logger
+		Validators  []Validator  `group:"config_validators"`
+		Subscribers []Subscriber `group:"config_subscribers"`
+	}
+
+	reloaderResult struct {
+		dig.Out
+
+		Reloader *Reloader
+		Service  service.Service `group:"services"`
+	}
+)
+
+const (
+	// WatchKey is the key of settings, that enables watch mode of config (`<PREFIX>_CONFIG_WATCH=true`):
+	// config is reloaded when the file was changed or SIGHUP was received.
+	WatchKey = "config.watch"
+
+	// ErrReloadRejected is raised when the new config could not be read or is invalid.
+	ErrReloadRejected = internal.Error("config reload rejected")
+
+	reloaderName = "config-reloader"
+	reloadDelay  = 100 * time.Millisecond
+)
+
+var _ service.Service = (*Reloader)(nil)
+
+// Changed returns true, when the key or any nested key was changed, for example `Changed("logger")`.
+func (e Event) Changed(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, change := range e.Changes {
+		if change.Key == key || strings.HasPrefix(change.Key, key+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Keys returns changed keys.
+func (e Event) Keys() []string {
+	keys := make([]string, 0, len(e.Changes))
+	for _, change := range e.Changes {
+		keys = append(keys, change.Key)
+	}
+
+	return keys
+}
+
+func newReloader(p reloaderParams) (reloaderResult, error) {
+	r := &Reloader{
+		app:        p.App,
+		origin:     p.Viper,
+		current:    atomic.NewPointer(p.Viper),
+		sources:    p.Sources,
+		secrets:    p.Secrets,
+		logger:     p.Logger,
+		validators: p.Validators,
+		done:       make(chan struct{}),
+	}
+
+	for _, fn := range p.Subscribers {
+		// constructors could skip subscription, e.g. disabled servers
+		if fn != nil {
+			r.subscribers = append(r.subscribers, fn)
+		}
+	}
+
+	var err error
+	if r.layers, err = readLayers(r.app); err != nil {
+		return reloaderResult{}, err
+	}
+
+	r.base = r.layers
+
+	return reloaderResult{Reloader: r, Service: r}, nil
+}
+
+// Subscribe adds subscriber, that is called when config was reloaded.
+func (r *Reloader) Subscribe(fn Subscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Settings returns the last applied settings, they are not changed after that, the new config is loaded
+// into new settings. Settings provided into DI are returned until the first successful reload.
+func (r *Reloader) Settings() *viper.Viper { return r.current.Load() }
+
+// Reload reads config files, checks them by config validators and publishes changes to subscribers.
+// When the new config could not be read or is invalid, it's rejected with ErrReloadRejected, settings are not changed.
+func (r *Reloader) Reload() error { return r.update(false) }
+
+// Refresh reloads config even when config files were not changed, cached secrets are resolved again (see Secrets).
+func (r *Reloader) Refresh() error { return r.update(true) }
+
+func (r *Reloader) update(refresh bool) error {
+	event, subscribers, err := r.apply(refresh)
+	if err != nil || len(event.Changes) == 0 {
+		return err
+	}
+
+	// subscribers are called without lock, so they could subscribe or reload config
+	for _, fn := range subscribers {
+		fn(event)
+	}
+
+	return nil
+}
+
+// apply loads and applies the new config, it returns the event of changes and subscribers, that should be notified.
+func (r *Reloader) apply(refresh bool) (Event, []Subscriber, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	layers, err := readLayers(r.app)
+	if err != nil {
+		return Event{}, nil, fmt.Errorf("%w: %v", ErrReloadRejected, err)
+	}
+
+	if !refresh && equalLayers(layers, r.layers) {
+		return Event{}, nil, nil
+	}
+
+	next, refs, cache, err := r.load(layers, refresh)
+	if err != nil {
+		return Event{}, nil, fmt.Errorf("%w: %v", ErrReloadRejected, err)
+	}
+
+	for _, validator := range r.validators {
+		if err = validator(next); err != nil {
+			return Event{}, nil, fmt.Errorf("%w: %v", ErrReloadRejected, err)
+		}
+	}
+
+	prev, secrets := r.current.Swap(next), r.secretKeys()
+
+	r.layers = layers
+
+	if r.secrets != nil {
+		r.secrets.commit(refs, cache)
+	}
+
+	if r.sources != nil {
+		// layers were already parsed, so the error is not expected
+		_ = r.sources.update(layers)
+	}
+
+	event := Event{Changes: diff(snapshot(prev), snapshot(next)), Settings: next}
+	if len(event.Changes) == 0 {
+		return event, nil, nil
+	}
+
+	for key := range r.secretKeys() {
+		secrets[key] = struct{}{}
+	}
+
+	for i := range event.Changes {
+		if _, ok := secrets[event.Changes[i].Key]; ok {
+			event.Changes[i].Old, event.Changes[i].New = redactValue(event.Changes[i].Old), redactValue(event.Changes[i].New)
+		}
+	}
+
+	r.logger.Info("config reloaded", zap.Strings("changed", event.Keys()))
+
+	return event, append([]Subscriber(nil), r.subscribers...), nil
+}
+
+// Start watches directories of config files and SIGHUP signal until the reloader is stopped.
+// SIGHUP and interval of SecretsRefreshKey (when it's set) refresh config and secrets (see Refresh).
+// Rejected reloads are logged, the application keeps running with the old config.
+func (r *Reloader) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = watcher.Close() }()
+
+	// directories are watched, because editors and k8s replace files instead of writing them,
+	// any change leads to reload, unchanged config is ignored
+	for _, dir := range r.directories() {
+		if err = watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	defer signal.Stop(signals)
+
+	// file is reloaded when it was not changed for a while, so partially written file is not read
+	debounce := time.NewTimer(reloadDelay)
+	debounce.Stop()
+
+	defer debounce.Stop()
+
+	var refresh <-chan time.Time
+
+	if interval := r.Settings().GetDuration(SecretsRefreshKey); interval > 0 {
+		ticker := time.NewTicker(interval)
+		refresh = ticker.C
+
+		defer ticker.Stop()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.done:
+			return nil
+		case <-signals:
+			r.reload("signal", r.Refresh)
+		case <-refresh:
+			r.reload("refresh", r.Refresh)
+		case <-debounce.C:
+			r.reload("file", r.Reload)
+		case event := <-watcher.Events:
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				debounce.Reset(reloadDelay)
+			}
+		case err = <-watcher.Errors:
+			r.logger.Warn("could not watch config files", zap.Error(err))
+		}
+	}
+}
+
+// Stop stops watching.
+func (r *Reloader) Stop(context.Context) {
+	r.once.Do(func() { close(r.done) })
+}
+
+// Name returns name of the service.
+func (r *Reloader) Name() string { return reloaderName }
+
+func (r *Reloader) reload(source string, fn func() error) {
+	if err := fn(); err != nil {
+		r.logger.Error("could not reload config",
+			zap.String("source", source),
+			zap.Error(err))
+	}
+}
+
+// directories returns directories of config files and config directories.
+func (r *Reloader) directories() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		result []string
+		seen   = make(map[string]struct{})
+	)
+
+	paths := append([]string(nil), r.app.Files...)
+	for _, l := range r.layers {
+		paths = append(paths, filepath.Dir(l.file))
+	}
+
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			path = filepath.Dir(path)
+		}
+
+		if path = filepath.Clean(path); path == "" {
+			continue
+		}
+
+		if _, ok := seen[path]; !ok {
+			seen[path] = struct{}{}
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
+
+// secretKeys returns set of keys, which values are secrets.
+func (r *Reloader) secretKeys() map[string]struct{} {
+	result := make(map[string]struct{})
+
+	if r.secrets == nil {
+		return result
+	}
+
+	for _, key := range r.secrets.Keys() {
+		result[key] = struct{}{}
+	}
+
+	return result
+}
+
+// secretRef returns reference to the secret by the key of settings.
+func (r *Reloader) secretRef(key string) (string, bool) {
+	if r.secrets == nil {
+		return "", false
+	}
+
+	return r.secrets.ref(key)
+}
+
+// load reads layers into new settings and resolves secrets, found references and resolved values of secrets
+// are returned to be committed after validation. Values that are not taken from config files
+// (defaults, environment variables and flags) are inherited from settings the application was started with.
+func (r *Reloader) load(layers []layer, refresh bool) (*viper.Viper, map[string]string, map[string]string, error) {
+	v, err := newViper(r.app)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	keys, err := layerKeys(r.base)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, key := range r.origin.AllKeys() {
+		if _, ok := keys[key]; ok {
+			continue
+		}
+
+		// references are inherited instead of values of secrets, so they are resolved again
+		if ref, ok := r.secretRef(key); ok {
+			v.SetDefault(key, ref)
+
+			continue
+		}
+
+		v.SetDefault(key, r.origin.Get(key))
+	}
+
+	if err = mergeLayers(v, layers); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if r.secrets == nil {
+		return v, nil, nil, nil
+	}
+
+	refs, cache, err := r.secrets.lookup(v, refresh)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return v, refs, cache, nil
+}
+
+func equalLayers(a, b []layer) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].file != b[i].file || a[i].typ != b[i].typ || !bytes.Equal(a[i].data, b[i].data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// redactValue hides value of the secret, missing value is kept as is.
+func redactValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return Redacted
+}
+
+func snapshot(v *viper.Viper) map[string]interface{} {
+	keys := v.AllKeys()
+
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		result[key] = v.Get(key)
+	}
+
+	return result
+}
+
+// diff returns changes sorted by keys.
+func diff(before, after map[string]interface{}) []Change {
+	var changes []Change
+
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes = append(changes, Change{Key: key, Old: before[key], New: value})
+		}
+	}
+
+	for key, value := range before {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, Change{Key: key, Old: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	return changes
+}
@@ -0,0 +1,274 @@
+package settings
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/module"
+)
+
+const errInvalidPort = internal.Error("invalid port")
+
+func TestReloader(t *testing.T) {
+	prepare := func(t *testing.T, content string, validators ...Validator) (*Reloader, *viper.Viper, string) {
+		file := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+		app := &Core{File: file, Type: "yml"}
+		v, err := New(app)
+		require.NoError(t, err)
+
+		res, err := newReloader(reloaderParams{App: app, Viper: v, Logger: zap.NewNop(), Validators: validators})
+		require.NoError(t, err)
+		require.Equal(t, res.Reloader, res.Service)
+
+		return res.Reloader, v, file
+	}
+
+	write := func(t *testing.T, file, content string) {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	t.Run("should publish changes", func(t *testing.T) {
+		r, v, file := prepare(t, "logger:\n  level: info\nport: 80\nflag: true\n")
+
+		var events []Event
+		r.Subscribe(func(e Event) { events = append(events, e) })
+
+		require.NoError(t, r.Reload())
+		require.Empty(t, events, "file was not changed")
+
+		write(t, file, "logger:\n  level: debug\nport: 80\nfeature: true\n")
+		require.NoError(t, r.Reload())
+		require.Len(t, events, 1)
+		require.Equal(t, []Change{
+			{Key: "feature", New: true},
+			{Key: "flag", Old: true},
+			{Key: "logger.level", Old: "info", New: "debug"},
+		}, events[0].Changes)
+		require.Equal(t, []string{"feature", "flag", "logger.level"}, events[0].Keys())
+		require.True(t, events[0].Changed("logger"))
+		require.True(t, events[0].Changed("Logger.Level"))
+		require.False(t, events[0].Changed("port"))
+		require.False(t, events[0].Changed("log"))
+		require.Equal(t, "debug", r.Settings().GetString("logger.level"))
+		require.Same(t, r.Settings(), events[0].Settings)
+		require.Equal(t, "info", v.GetString("logger.level"), "settings provided into DI are not changed")
+	})
+
+	t.Run("subscriber could subscribe and reload config", func(t *testing.T) {
+		r, _, file := prepare(t, "port: 80\n")
+
+		var (
+			calls int
+			added []Event
+		)
+
+		r.Subscribe(func(e Event) {
+			if calls++; calls > 1 {
+				return
+			}
+
+			r.Subscribe(func(e Event) { added = append(added, e) })
+
+			write(t, file, "port: 82\n")
+			require.NoError(t, r.Reload())
+		})
+
+		write(t, file, "port: 81\n")
+
+		done := make(chan error, 1)
+		go func() { done <- r.Reload() }()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second * 5):
+			t.Fatal("subscriber should not be called under lock")
+		}
+
+		require.Equal(t, 2, calls)
+		require.Len(t, added, 1)
+		require.Equal(t, 82, r.Settings().GetInt("port"))
+	})
+
+	t.Run("should load new config into new settings", func(t *testing.T) {
+		r, v, file := prepare(t, "port: 80\n")
+		v.SetDefault("workers", 10)
+
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() { // settings provided into DI could be read while config is reloaded
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					_ = v.GetInt("port")
+				}
+			}
+		}()
+
+		write(t, file, "port: 81\n")
+		require.NoError(t, r.Reload())
+		require.Equal(t, 81, r.Settings().GetInt("port"))
+		require.Equal(t, 10, r.Settings().GetInt("workers"), "defaults should be kept")
+		require.Equal(t, 80, v.GetInt("port"))
+	})
+
+	t.Run("should reject invalid config", func(t *testing.T) {
+		r, v, file := prepare(t, "port: 80\n", func(v *viper.Viper) error {
+			if v.GetInt("port") <= 0 {
+				return errInvalidPort
+			}
+
+			return nil
+		})
+
+		r.Subscribe(func(Event) { t.Fatal("should not be called") })
+
+		write(t, file, "port: -1\n")
+		err := r.Reload()
+		require.ErrorIs(t, err, ErrReloadRejected)
+		require.Contains(t, err.Error(), errInvalidPort.Error())
+		require.Same(t, v, r.Settings())
+		require.Equal(t, 80, v.GetInt("port"))
+
+		write(t, file, "port: [")
+		require.ErrorIs(t, r.Reload(), ErrReloadRejected)
+		require.Same(t, v, r.Settings())
+
+		require.NoError(t, os.Remove(file))
+		require.ErrorIs(t, r.Reload(), ErrReloadRejected)
+		require.Same(t, v, r.Settings())
+		require.Equal(t, 80, v.GetInt("port"))
+	})
+
+	t.Run("should reload all layers", func(t *testing.T) {
+		dir := t.TempDir()
+		base := filepath.Join(dir, "base.yaml")
+		secret := filepath.Join(dir, "conf.d", "secret.json")
+
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "conf.d"), 0o700))
+		write(t, base, "db:\n  host: localhost\n")
+		write(t, secret, `{"db": {"password": "old"}}`)
+
+		app := &Core{File: base, Files: []string{filepath.Join(dir, "conf.d")}}
+		v, sources, _, err := newSettings(settingsParams{App: app})
+		require.NoError(t, err)
+
+		res, err := newReloader(reloaderParams{App: app, Viper: v, Sources: sources, Logger: zap.NewNop()})
+		require.NoError(t, err)
+
+		var events []Event
+		res.Reloader.Subscribe(func(e Event) { events = append(events, e) })
+
+		write(t, secret, `{"db": {"password": "new", "user": "app"}}`)
+		require.NoError(t, res.Reloader.Reload())
+		require.Len(t, events, 1)
+		require.Equal(t, []string{"db.password", "db.user"}, events[0].Keys())
+		require.Equal(t, "localhost", res.Reloader.Settings().GetString("db.host"))
+		require.Equal(t, "new", res.Reloader.Settings().GetString("db.password"))
+		require.Equal(t, "old", v.GetString("db.password"))
+		require.Equal(t, secret, sources.Source("db.user"))
+		require.ElementsMatch(t, []string{dir, filepath.Join(dir, "conf.d")}, res.Reloader.directories())
+
+		write(t, secret, `{"db": `)
+		require.ErrorIs(t, res.Reloader.Reload(), ErrReloadRejected)
+		require.Equal(t, "localhost", res.Reloader.Settings().GetString("db.host"))
+		require.Equal(t, "new", res.Reloader.Settings().GetString("db.password"))
+	})
+
+	t.Run("should do nothing without config file", func(t *testing.T) {
+		res, err := newReloader(reloaderParams{App: &Core{}, Viper: viper.New(), Logger: zap.NewNop()})
+		require.NoError(t, err)
+		require.NoError(t, res.Reloader.Reload())
+	})
+
+	t.Run("should fail on unknown config file", func(t *testing.T) {
+		_, err := newReloader(reloaderParams{App: &Core{File: "unknown file"}, Viper: viper.New(), Logger: zap.NewNop()})
+		require.ErrorIs(t, err, ErrConfig)
+	})
+
+	t.Run("should reload on file change and SIGHUP", func(t *testing.T) {
+		r, v, file := prepare(t, "port: 80\n")
+
+		events := make(chan Event, 1)
+		r.Subscribe(func(e Event) { events <- e })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() { done <- r.Start(ctx) }()
+
+		// waiting to run the watcher and channel of signals
+		<-time.After(50 * time.Millisecond)
+
+		write(t, file, "port: 81\n")
+
+		select {
+		case e := <-events:
+			require.True(t, e.Changed("port"))
+			require.Equal(t, 81, e.Settings.GetInt("port"))
+		case <-ctx.Done():
+			t.Fatal("config was not reloaded on file change")
+		}
+
+		// file is changed without notification, SIGHUP forces reload
+		r.mu.Lock()
+		r.layers = nil
+		r.current.Store(v)
+		r.mu.Unlock()
+
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+		select {
+		case e := <-events:
+			require.Equal(t, []Change{{Key: "port", Old: 80, New: 81}}, e.Changes)
+		case <-ctx.Done():
+			t.Fatal("config was not reloaded on SIGHUP")
+		}
+
+		r.Stop(ctx)
+		r.Stop(ctx)
+		require.NoError(t, <-done)
+		require.Equal(t, reloaderName, r.Name())
+	})
+}
+
+func TestWatchMode(t *testing.T) {
+	provide := func(t *testing.T, content string) *dig.Container {
+		file := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+		di := dig.New()
+		require.NoError(t, module.Provide(di, module.Module{
+			{Constructor: func() *Core { return &Core{File: file, Type: "yml"} }},
+			{Constructor: zap.NewNop},
+		}.Append(Module)))
+
+		return di
+	}
+
+	t.Run("should provide reloader when watch mode is enabled", func(t *testing.T) {
+		di := provide(t, "config:\n  watch: true\n")
+		require.NoError(t, di.Invoke(func(*Reloader) {}))
+	})
+
+	t.Run("should not provide reloader by default", func(t *testing.T) {
+		di := provide(t, "port: 80\n")
+		require.Error(t, di.Invoke(func(*Reloader) {}))
+	})
+}
@@ -0,0 +1,348 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/internal"
+)
+
+type (
+	// SecretProvider resolves references to secrets with its scheme, e.g. `vault://db/password`.
+	// Providers should be provided into DI by `group:"secret_providers"` (see SecretProviderResult).
+	SecretProvider interface {
+		// Scheme of references, e.g. `vault`.
+		Scheme() string
+
+		// Resolve returns value of the secret by the path of reference, e.g. `db/password`.
+		Resolve(path string) (string, error)
+	}
+
+	// SecretProviderResult allows to provide SecretProvider into DI.
+	SecretProviderResult struct {
+		dig.Out
+
+		Provider SecretProvider `group:"secret_providers"`
+	}
+
+	// Secrets resolves references to secrets in config values (`file:///run/secrets/db_password`, `env://DB_PASS`
+	// or `<scheme>://path` of custom SecretProvider) and replaces them by values of secrets.
+	// Resolved values are cached by reference until Refresh, they are redacted in dumps of settings (see Redact).
+	Secrets struct {
+		mu sync.Mutex
+
+		providers map[string]SecretProvider
+		cache     map[string]string
+		refs      map[string]string
+	}
+
+	// redactedError hides values of secrets in the text of the error.
+	redactedError struct {
+		error
+		text string
+	}
+
+	fileProvider struct{}
+	envProvider  struct{}
+)
+
+const (
+	// ErrSecretNotFound is raised by secret providers when the secret does not exist.
+	ErrSecretNotFound = internal.Error("secret not found")
+
+	// SecretsRefreshKey is the key of settings, that sets interval of secrets refresh in watch mode (see Reloader).
+	SecretsRefreshKey = "config.secrets.refresh"
+
+	// Redacted replaces values of secrets in dumps of settings and config reload events.
+	Redacted = "[REDACTED]"
+
+	schemeSeparator = "://"
+)
+
+var (
+	_ SecretProvider = fileProvider{}
+	_ SecretProvider = envProvider{}
+)
+
+// NewSecrets creates secrets resolver with `file` and `env` providers,
+// passed providers are added to them, provider with the same scheme replaces the previous one.
+func NewSecrets(providers ...SecretProvider) *Secrets {
+	s := &Secrets{
+		providers: make(map[string]SecretProvider, len(providers)+2),
+		cache:     make(map[string]string),
+		refs:      make(map[string]string),
+	}
+
+	for _, provider := range append([]SecretProvider{fileProvider{}, envProvider{}}, providers...) {
+		s.providers[strings.ToLower(provider.Scheme())] = provider
+	}
+
+	return s
+}
+
+// Keys returns sorted keys of settings, which values are secrets.
+func (s *Secrets) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.refs))
+	for key := range s.refs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// IsSecret returns true, when value of the key is secret.
+func (s *Secrets) IsSecret(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.refs[strings.ToLower(key)]
+
+	return ok
+}
+
+// Redact returns copy of settings (see viper.AllSettings), values of secrets are replaced by Redacted.
+func (s *Secrets) Redact(settings map[string]interface{}) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return redact(settings, "", s.refs)
+}
+
+// RedactText replaces values of secrets in the text, e.g. in error messages.
+func (s *Secrets) RedactText(text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, value := range s.cache {
+		if value != "" {
+			text = strings.ReplaceAll(text, value, Redacted)
+		}
+	}
+
+	return text
+}
+
+// Refresh resolves secrets again without cache, so rotated secrets are applied to settings.
+// When any secret could not be resolved, settings and cache are not changed.
+func (s *Secrets) Refresh(v *viper.Viper) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cache, err := s.apply(v, s.refs, true)
+	if err != nil {
+		return err
+	}
+
+	s.cache = cache
+
+	return nil
+}
+
+// resolve finds references to secrets in settings and replaces them by values of secrets.
+// It should be called every time the config was read, because references are kept only in config files.
+func (s *Secrets) resolve(v *viper.Viper, fresh bool) error {
+	refs, cache, err := s.lookup(v, fresh)
+	if err != nil {
+		return err
+	}
+
+	s.commit(refs, cache)
+
+	return nil
+}
+
+// lookup finds references to secrets in settings and replaces them by values of secrets,
+// found references and resolved values are returned without changes of Secrets (see commit),
+// so the new config could be rejected.
+func (s *Secrets) lookup(v *viper.Viper, fresh bool) (map[string]string, map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refs := make(map[string]string)
+
+	for _, key := range v.AllKeys() {
+		if ref, ok := v.Get(key).(string); ok && s.reference(ref) {
+			refs[key] = ref
+		}
+	}
+
+	cache, err := s.apply(v, refs, fresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return refs, cache, nil
+}
+
+// commit replaces references and cache of resolved values by the new ones (see lookup).
+func (s *Secrets) commit(refs, cache map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refs, s.cache = refs, cache
+}
+
+// apply resolves references and sets values of secrets into settings, it returns cache of used references.
+// Values are set over all sources of settings, because references could be taken from environment variables and flags.
+// Cached values are ignored, when fresh is true.
+func (s *Secrets) apply(v *viper.Viper, refs map[string]string, fresh bool) (map[string]string, error) {
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+
+	// errors are reported in the same order every time
+	sort.Strings(keys)
+
+	var (
+		cache  = make(map[string]string, len(refs))
+		values = make([]string, 0, len(refs))
+	)
+
+	for _, key := range keys {
+		ref := refs[key]
+
+		value, ok := cache[ref]
+		if !ok && !fresh {
+			value, ok = s.cache[ref]
+		}
+
+		if !ok {
+			var err error
+			if value, err = s.secret(ref); err != nil {
+				return nil, fmt.Errorf("%w: secret %q of %q: %v", ErrConfig, ref, key, err)
+			}
+		}
+
+		cache[ref] = value
+		values = append(values, value)
+	}
+
+	// settings are changed only when all secrets were resolved
+	for i, key := range keys {
+		v.Set(key, values[i])
+	}
+
+	return cache, nil
+}
+
+// ref returns reference to the secret by the key of settings.
+func (s *Secrets) ref(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ref, ok := s.refs[key]
+
+	return ref, ok
+}
+
+// secret resolves the reference by provider of its scheme.
+func (s *Secrets) secret(ref string) (string, error) {
+	idx := strings.Index(ref, schemeSeparator)
+
+	return s.providers[strings.ToLower(ref[:idx])].Resolve(ref[idx+len(schemeSeparator):])
+}
+
+// reference returns true for values with scheme of known provider,
+// other URLs (e.g. `postgres://localhost`) are not references.
+func (s *Secrets) reference(value string) bool {
+	idx := strings.Index(value, schemeSeparator)
+	if idx <= 0 {
+		return false
+	}
+
+	_, ok := s.providers[strings.ToLower(value[:idx])]
+
+	return ok
+}
+
+// redact copies nested settings and replaces values of secret keys.
+func redact(settings map[string]interface{}, prefix string, refs map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(settings))
+
+	for name, value := range settings {
+		key := join(prefix, name)
+
+		if _, ok := refs[key]; ok {
+			result[name] = Redacted
+
+			continue
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = redact(nested, key, refs)
+		}
+
+		result[name] = value
+	}
+
+	return result
+}
+
+// Error returns text of the error without secrets.
+func (e redactedError) Error() string { return e.text }
+
+// Unwrap returns the original error.
+func (e redactedError) Unwrap() error { return e.error }
+
+// redactError hides values of secrets in the error, violations are kept, so they could be taken by errors.As.
+func redactError(err error, secrets *Secrets) error {
+	if err == nil || secrets == nil {
+		return err
+	}
+
+	var violations Violations
+	if errors.As(err, &violations) {
+		result := make(Violations, len(violations))
+		for i := range violations {
+			result[i] = violations[i]
+			result[i].Message = secrets.RedactText(violations[i].Message)
+		}
+
+		return result
+	}
+
+	if text := secrets.RedactText(err.Error()); text != err.Error() {
+		return redactedError{error: err, text: text}
+	}
+
+	return err
+}
+
+// Scheme of file secrets, e.g. `file:///run/secrets/db_password`.
+func (fileProvider) Scheme() string { return "file" }
+
+// Resolve reads the file, trailing line break is trimmed.
+func (fileProvider) Resolve(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Scheme of environment secrets, e.g. `env://DB_PASS`.
+func (envProvider) Scheme() string { return "env" }
+
+// Resolve returns value of environment variable.
+func (envProvider) Resolve(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %q", ErrSecretNotFound, name)
+	}
+
+	return value, nil
+}
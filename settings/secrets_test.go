@@ -0,0 +1,240 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/module"
+)
+
+type testVault struct {
+	calls   int
+	secrets map[string]string
+}
+
+var _ SecretProvider = (*testVault)(nil)
+
+func (v *testVault) Scheme() string { return "vault" }
+
+func (v *testVault) Resolve(path string) (string, error) {
+	v.calls++
+
+	value, ok := v.secrets[path]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+
+	return value, nil
+}
+
+func TestSecrets(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db_password")
+	require.NoError(t, os.WriteFile(secret, []byte("from-file\n"), 0o600))
+
+	config := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+db:
+  dsn: postgres://localhost:5432/db
+  password: file://`+secret+`
+  user: env://SECRETS_TEST_USER
+api:
+  token: vault://api/token
+  backup: vault://api/token
+`), 0o600))
+
+	t.Setenv("SECRETS_TEST_USER", "from-env")
+
+	t.Run("should resolve secrets by providers", func(t *testing.T) {
+		vault := &testVault{secrets: map[string]string{"api/token": "from-vault"}}
+
+		v, err := New(&Core{File: config}, vault)
+		require.NoError(t, err)
+		require.Equal(t, "from-file", v.GetString("db.password"))
+		require.Equal(t, "from-env", v.GetString("db.user"))
+		require.Equal(t, "from-vault", v.GetString("api.token"))
+		require.Equal(t, "from-vault", v.GetString("api.backup"))
+		require.Equal(t, "postgres://localhost:5432/db", v.GetString("db.dsn"))
+		require.Equal(t, 1, vault.calls, "secrets should be cached by reference")
+
+		var cfg struct {
+			Password string `mapstructure:"password"`
+		}
+
+		require.NoError(t, v.UnmarshalKey("db", &cfg))
+		require.Equal(t, "from-file", cfg.Password)
+	})
+
+	t.Run("should resolve references from environment variables", func(t *testing.T) {
+		t.Setenv("APP_DB_PASSWORD", "env://DB_PASS_SECRET")
+		t.Setenv("APP_API_TOKEN", "vault://api/token")
+		t.Setenv("DB_PASS_SECRET", "from-env-ref")
+
+		vault := &testVault{secrets: map[string]string{"api/token": "old"}}
+
+		app := &Core{File: config, Prefix: "APP"}
+		v, sources, secrets, err := newSettings(settingsParams{App: app, Providers: []SecretProvider{vault}})
+		require.NoError(t, err)
+		require.Equal(t, "from-env-ref", v.GetString("db.password"))
+		require.Equal(t, "old", v.GetString("api.token"))
+		require.Equal(t, Redacted, secrets.Redact(v.AllSettings())["db"].(map[string]interface{})["password"])
+
+		var cfg struct {
+			Password string `mapstructure:"password"`
+		}
+
+		require.NoError(t, v.UnmarshalKey("db", &cfg))
+		require.Equal(t, "from-env-ref", cfg.Password)
+
+		res, err := newReloader(reloaderParams{App: app, Viper: v, Sources: sources, Secrets: secrets, Logger: zap.NewNop()})
+		require.NoError(t, err)
+
+		vault.secrets["api/token"] = "new"
+		require.NoError(t, res.Reloader.Refresh())
+		require.Equal(t, "from-env-ref", res.Reloader.Settings().GetString("db.password"))
+		require.Equal(t, "new", res.Reloader.Settings().GetString("api.token"))
+		require.True(t, secrets.IsSecret("db.password"))
+	})
+
+	t.Run("should fail on unresolved secrets", func(t *testing.T) {
+		_, err := New(&Core{File: config})
+		require.NoError(t, err, "unknown schemes are not references")
+
+		_, err = New(&Core{File: config}, &testVault{})
+		require.ErrorIs(t, err, ErrConfig)
+		require.Contains(t, err.Error(), `secret "vault://api/token" of "api.backup"`)
+
+		require.NoError(t, os.Unsetenv("SECRETS_TEST_USER"))
+		defer func() { require.NoError(t, os.Setenv("SECRETS_TEST_USER", "from-env")) }()
+
+		_, err = New(&Core{File: config})
+		require.ErrorIs(t, err, ErrConfig)
+		require.Contains(t, err.Error(), ErrSecretNotFound.Error())
+	})
+
+	t.Run("should refresh and redact secrets", func(t *testing.T) {
+		vault := &testVault{secrets: map[string]string{"api/token": "old"}}
+
+		di := dig.New()
+		require.NoError(t, module.Provide(di, module.Module{
+			{Constructor: func() *Core { return &Core{File: config} }},
+			{Constructor: func() SecretProviderResult { return SecretProviderResult{Provider: vault} }},
+		}.Append(Module)))
+
+		require.NoError(t, di.Invoke(func(s *Secrets, sources *Sources) {
+			v := Viper()
+			require.Equal(t, []string{"api.backup", "api.token", "db.password", "db.user"}, s.Keys())
+			require.True(t, s.IsSecret("DB.Password"))
+			require.False(t, s.IsSecret("db.dsn"))
+			require.Equal(t, config, sources.Source("db.password"))
+
+			require.Equal(t, map[string]interface{}{
+				"api": map[string]interface{}{"token": Redacted, "backup": Redacted},
+				"db": map[string]interface{}{
+					"dsn":      "postgres://localhost:5432/db",
+					"password": Redacted,
+					"user":     Redacted,
+				},
+			}, s.Redact(v.AllSettings()))
+
+			require.Equal(t, 1, vault.calls, "settings should be built only once")
+
+			vault.secrets["api/token"] = "new"
+			require.NoError(t, s.Refresh(v))
+			require.Equal(t, "new", v.GetString("api.token"))
+			require.Equal(t, 2, vault.calls)
+
+			delete(vault.secrets, "api/token")
+			require.ErrorIs(t, s.Refresh(v), ErrConfig)
+			require.Equal(t, "new", v.GetString("api.token"))
+		}))
+	})
+
+	t.Run("should redact secrets in errors of bound config", func(t *testing.T) {
+		di := dig.New()
+		require.NoError(t, module.Provide(di, module.Module{
+			{Constructor: func() *Core { return &Core{File: config} }},
+			{Constructor: func() SecretProviderResult {
+				return SecretProviderResult{Provider: &testVault{secrets: map[string]string{"api/token": "top-secret"}}}
+			}},
+		}.Append(Module, Bind[struct {
+			Token  string `mapstructure:"token" validate:"oneof=a b"`
+			Backup int    `mapstructure:"backup"`
+		}]("api"))))
+
+		err := di.Invoke(func(p struct {
+			dig.In
+
+			Viper      *viper.Viper
+			Validators []Validator `group:"config_validators"`
+		},
+		) {
+			require.Len(t, p.Validators, 1)
+
+			err := p.Validators[0](p.Viper)
+			require.ErrorIs(t, err, ErrConfig)
+			require.NotContains(t, err.Error(), "top-secret")
+			require.Contains(t, err.Error(), Redacted)
+		})
+		require.NoError(t, err)
+
+		v, _, secrets, err := newSettings(settingsParams{
+			App:       &Core{File: config},
+			Providers: []SecretProvider{&testVault{secrets: map[string]string{"api/token": "top-secret"}}},
+		})
+		require.NoError(t, err)
+
+		err = redactError(Unmarshal(v, "api", new(struct {
+			Token  string `mapstructure:"token" validate:"oneof=a b"`
+			Backup string `mapstructure:"backup"`
+		})), secrets)
+
+		var violations Violations
+		require.ErrorAs(t, err, &violations)
+		require.Equal(t, Violations{
+			{Key: "api.token", Rule: ruleOneOf, Message: "must be one of [a, b], got " + Redacted},
+		}, violations)
+	})
+
+	t.Run("should reload secrets and redact changes", func(t *testing.T) {
+		vault := &testVault{secrets: map[string]string{"api/token": "old"}}
+
+		app := &Core{File: config}
+		v, sources, secrets, err := newSettings(settingsParams{App: app, Providers: []SecretProvider{vault}})
+		require.NoError(t, err)
+
+		res, err := newReloader(reloaderParams{App: app, Viper: v, Sources: sources, Secrets: secrets, Logger: zap.NewNop()})
+		require.NoError(t, err)
+
+		var events []Event
+		res.Reloader.Subscribe(func(e Event) { events = append(events, e) })
+
+		require.NoError(t, res.Reloader.Reload())
+		require.Empty(t, events, "config was not changed")
+
+		vault.secrets["api/token"] = "new"
+		require.NoError(t, res.Reloader.Reload())
+		require.Empty(t, events, "cached secrets are used until refresh")
+
+		require.NoError(t, res.Reloader.Refresh())
+		require.Len(t, events, 1)
+		require.Equal(t, []Change{
+			{Key: "api.backup", Old: Redacted, New: Redacted},
+			{Key: "api.token", Old: Redacted, New: Redacted},
+		}, events[0].Changes)
+		require.Equal(t, "new", res.Reloader.Settings().GetString("api.token"))
+		require.Equal(t, "old", v.GetString("api.token"))
+
+		delete(vault.secrets, "api/token")
+		require.ErrorIs(t, res.Reloader.Refresh(), ErrReloadRejected)
+		require.Equal(t, "new", res.Reloader.Settings().GetString("api.token"))
+		require.Equal(t, "from-file", res.Reloader.Settings().GetString("db.password"))
+		require.Equal(t, []string{"api.backup", "api.token", "db.password", "db.user"}, secrets.Keys())
+	})
+}
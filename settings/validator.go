@@ -0,0 +1,20 @@
+package settings
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
+)
+
+type (
+	// Validator checks passed settings, it should not bind sockets or start anything.
+	// helium.Validate passes settings of the application, Reloader passes the new config before it is applied.
+	// Validators should be provided into DI by `group:"config_validators"`.
+	Validator func(v *viper.Viper) error
+
+	// ValidatorResult allows to provide Validator into DI.
+	ValidatorResult struct {
+		dig.Out
+
+		Validator Validator `group:"config_validators"`
+	}
+)
@@ -0,0 +1,176 @@
+package helium
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/dig"
+
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/settings"
+)
+
+type (
+	// Problem found by Validate, it contains the source of the problem
+	// (constructor, application or config) and the error.
+	Problem struct {
+		Source string
+		Err    error
+	}
+
+	// ValidationError contains all problems found by Validate.
+	ValidationError []Problem
+
+	validatorsParams struct {
+		dig.In
+
+		Viper      *viper.Viper         `optional:"true"`
+		Validators []settings.Validator `group:"config_validators"`
+	}
+)
+
+const (
+	sourceApp    = "application"
+	sourceConfig = "config"
+)
+
+var _ error = (ValidationError)(nil)
+
+// Error returns all problems as readable list.
+func (e ValidationError) Error() string {
+	list := make([]string, 0, len(e)+1)
+	list = append(list, fmt.Sprintf("validation failed, found %d problem(s):", len(e)))
+
+	for i := range e {
+		list = append(list, fmt.Sprintf("  - %s: %v", e[i].Source, e[i].Err))
+	}
+
+	return strings.Join(list, "\n")
+}
+
+// Is allows to check any of problems by errors.Is.
+func (e ValidationError) Is(target error) bool {
+	for i := range e {
+		if errors.Is(e[i].Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As allows to check any of problems by errors.As.
+func (e ValidationError) As(target interface{}) bool {
+	for i := range e {
+		if errors.As(e[i].Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks the application without running it:
+// - dependencies of every constructor and of the App are resolved in dry-run mode,
+// constructors are not called, so sockets are not bound and services are not started.
+// - config validators (see settings.Validator) are called.
+// It returns ValidationError that contains all problems.
+func (h Helium) Validate() error {
+	problems := h.resolve()
+	problems = append(problems, h.validateConfig()...)
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return ValidationError(problems)
+}
+
+// resolve resolves dependencies of every constructor and of the App in dry-run container.
+func (h Helium) resolve() []Problem {
+	dry := dig.New(dig.DryRun(true))
+	if err := module.Provide(dry, h.modules); err != nil {
+		return []Problem{{Source: sourceApp, Err: err}}
+	}
+
+	var problems []Problem
+
+	for _, p := range h.modules {
+		if p == nil || p.Constructor == nil {
+			continue
+		}
+
+		if err := dry.Invoke(dependencies(p.Constructor)); err != nil {
+			problems = append(problems, Problem{Source: funcName(p.Constructor), Err: dig.RootCause(err)})
+		}
+	}
+
+	if err := dry.Invoke(func(App) {}); err != nil {
+		problems = append(problems, Problem{Source: sourceApp, Err: dig.RootCause(err)})
+	}
+
+	return problems
+}
+
+// validateConfig calls config validators, problems are sorted because validators are passed in random order.
+func (h Helium) validateConfig() []Problem {
+	var problems []Problem
+
+	err := h.di.Invoke(func(p validatorsParams) {
+		for _, validator := range p.Validators {
+			if err := validator(p.Viper); err != nil {
+				problems = append(problems, Problem{Source: sourceConfig, Err: err})
+			}
+		}
+	})
+	if err != nil {
+		problems = append(problems, Problem{Source: sourceConfig, Err: dig.RootCause(err)})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Err.Error() < problems[j].Err.Error()
+	})
+
+	return problems
+}
+
+// dependencies returns function that requires the same dependencies as passed constructor.
+func dependencies(constructor interface{}) interface{} {
+	fn := reflect.TypeOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return constructor
+	}
+
+	args := make([]reflect.Type, 0, fn.NumIn())
+	for i := 0; i < fn.NumIn(); i++ {
+		// variadic arguments are not resolved by DI
+		if fn.IsVariadic() && i == fn.NumIn()-1 {
+			break
+		}
+
+		args = append(args, fn.In(i))
+	}
+
+	return reflect.MakeFunc(reflect.FuncOf(args, nil, false), func([]reflect.Value) []reflect.Value {
+		return nil
+	}).Interface()
+}
+
+// funcName returns name and location of the function.
+func funcName(fn interface{}) string {
+	pc := reflect.ValueOf(fn).Pointer()
+
+	info := runtime.FuncForPC(pc)
+	if info == nil {
+		return fmt.Sprintf("%T", fn)
+	}
+
+	file, line := info.FileLine(pc)
+
+	return fmt.Sprintf("%s (%s:%d)", info.Name(), file, line)
+}
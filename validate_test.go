@@ -0,0 +1,86 @@
+package helium
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/service"
+	"github.com/im-kulikov/helium/settings"
+	"github.com/im-kulikov/helium/web"
+)
+
+type (
+	missingFirst  struct{}
+	missingSecond struct{}
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("should report all unresolved dependencies without calling constructors", func(t *testing.T) {
+		var called int
+
+		h, err := New(nil, module.Module{
+			{Constructor: func(*missingFirst) int { called++; return 1 }},
+			{Constructor: func(*missingSecond, int) string { called++; return "" }},
+			{Constructor: func(int) App { called++; return heliumApp{} }},
+		})
+		require.NoError(t, err)
+
+		err = h.Validate()
+		require.Zero(t, called)
+
+		var problems ValidationError
+		require.ErrorAs(t, err, &problems)
+		require.Len(t, problems, 4) // two constructors, App constructor and App itself
+
+		require.Contains(t, err.Error(), "validation failed, found 4 problem(s):")
+		require.Contains(t, err.Error(), "helium.missingFirst")
+		require.Contains(t, err.Error(), "helium.missingSecond")
+		require.Contains(t, problems[0].Source, "validate_test.go")
+		require.Equal(t, sourceApp, problems[3].Source)
+	})
+
+	t.Run("should check listen addresses without binding sockets", func(t *testing.T) {
+		v := viper.New()
+		web.OpsDefaults(v)
+		v.Set("ops.address", "127.0.0.1:0")
+		v.Set("api.address", "localhost:http")
+
+		h, err := New(nil, module.Module{
+			{Constructor: func() *viper.Viper { return v }},
+			{Constructor: zap.NewNop},
+			{Constructor: func() context.Context { return context.Background() }},
+			{Constructor: func(grp service.Group) App { return grp }},
+		}, web.OpsModule, web.APIModule, service.Module)
+		require.NoError(t, err)
+		require.NoError(t, h.Validate())
+
+		v.Set("ops.address", "localhost")
+		v.Set("api.address", "localhost:unknown-port")
+
+		err = h.Validate()
+		require.ErrorIs(t, err, web.ErrInvalidAddress)
+
+		var problems ValidationError
+		require.ErrorAs(t, err, &problems)
+		require.Len(t, problems, 2)
+		require.Equal(t, sourceConfig, problems[0].Source)
+		require.Contains(t, problems[0].Err.Error(), "api.address")
+		require.Contains(t, problems[1].Err.Error(), "ops.address")
+	})
+
+	t.Run("should call custom validators", func(t *testing.T) {
+		h, err := New(nil, module.Module{
+			{Constructor: func() App { return heliumApp{} }},
+			{Constructor: func() settings.ValidatorResult {
+				return settings.ValidatorResult{Validator: func(*viper.Viper) error { return errTest }}
+			}},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, h.Validate(), errTest)
+	})
+}
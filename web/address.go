@@ -0,0 +1,66 @@
+package web
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/viper"
+
+	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/settings"
+)
+
+const (
+	// ErrInvalidAddress is raised when listen address could not be parsed.
+	ErrInvalidAddress = internal.Error("invalid address")
+
+	// ErrUnknownNetwork is raised when listen network is not supported.
+	ErrUnknownNetwork = internal.Error("unknown network")
+)
+
+// CheckAddress checks listen address of the network without binding it.
+func CheckAddress(network, address string) error {
+	switch network {
+	case "", "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		if network == "" {
+			network = "tcp"
+		}
+
+		_, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("%w %q: %v", ErrInvalidAddress, address, err)
+		}
+
+		if _, err = net.LookupPort(network, port); err != nil {
+			return fmt.Errorf("%w %q: %v", ErrInvalidAddress, address, err)
+		}
+
+		return nil
+	case "unix", "unixgram", "unixpacket":
+		if address == "" {
+			return fmt.Errorf("%w: empty socket path", ErrInvalidAddress)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownNetwork, network)
+	}
+}
+
+// addressValidator returns constructor of validator for listen address of the server by its settings key.
+func addressValidator(key string) func() settings.ValidatorResult {
+	return func() settings.ValidatorResult {
+		return settings.ValidatorResult{Validator: func(v *viper.Viper) error {
+			if v == nil || v.GetBool(key+".disabled") || !v.IsSet(key+".address") {
+				return nil
+			}
+
+			err := CheckAddress(v.GetString(key+".network"), v.GetString(key+".address"))
+			if err != nil {
+				return fmt.Errorf("%s.address: %w", key, err)
+			}
+
+			return nil
+		}}
+	}
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAddress(t *testing.T) {
+	cases := []struct {
+		network string
+		address string
+		expect  error
+	}{
+		{address: ":8080"},
+		{network: "tcp4", address: "127.0.0.1:0"},
+		{network: "udp", address: "[::1]:53"},
+		{network: "tcp", address: "localhost:http"},
+		{network: "unix", address: "/tmp/app.sock"},
+
+		{address: "localhost", expect: ErrInvalidAddress},
+		{address: ":unknown-port", expect: ErrInvalidAddress},
+		{network: "unix", expect: ErrInvalidAddress},
+		{network: "ip4", address: "127.0.0.1", expect: ErrUnknownNetwork},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.network+" "+tt.address, func(t *testing.T) {
+			err := CheckAddress(tt.network, tt.address)
+			if tt.expect == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.expect)
+		})
+	}
+}
+
+func TestAddressValidator(t *testing.T) {
+	v := viper.New()
+
+	validator := addressValidator(apiServer)().Validator
+	require.NoError(t, validator(v), "address is not set")
+
+	v.Set("api.address", "bad")
+	require.ErrorIs(t, validator(v), ErrInvalidAddress)
+	require.Contains(t, validator(v).Error(), "api.address")
+
+	v.Set("api.disabled", true)
+	require.NoError(t, validator(v), "server is disabled")
+
+	require.NoError(t, validator(nil), "empty settings")
+}
@@ -22,6 +22,7 @@ type (
 		listener   net.Listener
 		logger     *zap.Logger
 		server     *grpc.Server
+		ready      readiness
 	}
 
 	// GRPCOption allows changing default gRPC
@@ -38,6 +39,11 @@ const (
 	ErrEmptyGRPCAddress = internal.Error("empty gRPC address")
 )
 
+var (
+	_ service.Readiness  = (*gRPC)(nil)
+	_ service.Shutdowner = (*gRPC)(nil)
+)
+
 // GRPCSkipErrors allows to skip any errors.
 func GRPCSkipErrors() GRPCOption {
 	return func(g *gRPC) {
@@ -92,34 +98,36 @@ func NewGRPCService(serve *grpc.Server, opts ...GRPCOption) (service.Service, er
 		network:    "tcp",
 		skipErrors: false,
 		logger:     zap.L(),
-		name:       "unknown",
 	}
 
 	for i := range opts {
 		opts[i](s)
 	}
 
-	if s.listener != nil {
-		return s, nil
+	if s.listener != nil && s.address == "" {
+		s.address = s.listener.Addr().String()
 	}
 
 	if s.address == "" {
 		return nil, ErrEmptyGRPCAddress
 	}
 
-	var err error
-	if s.listener, err = net.Listen(s.network, s.address); err != nil {
-		return nil, s.catch(err)
-	}
-
 	return s, nil
 }
 
-// Name returns name of the service.
+// Name returns name of the service, that could be used in services configs,
+// the address is a part of the name only when the service has no name.
 func (g *gRPC) Name() string {
-	return fmt.Sprintf("gRPC(%s) %s", g.name, g.listener.Addr())
+	if g.name == "" {
+		return fmt.Sprintf("gRPC(%s)", g.address)
+	}
+
+	return fmt.Sprintf("gRPC(%s)", g.name)
 }
 
+// Ready returns channel that will be closed when gRPC service is serving.
+func (g *gRPC) Ready() <-chan struct{} { return g.ready.channel() }
+
 // Start tries to start gRPC service.
 // If something went wrong it returns an error.
 // If service could not start returns an error.
@@ -128,24 +136,44 @@ func (g *gRPC) Start(context.Context) error {
 		return ErrEmptyGRPCServer
 	}
 
+	// the listener is bound on the first start, so the service fails on start instead of its creation
+	if g.listener == nil {
+		lis, err := net.Listen(g.network, g.address)
+		if err != nil {
+			return g.catch(err)
+		}
+
+		g.listener = lis
+	}
+
 	g.logger.Info("starting gRPC server",
 		zap.String("name", g.name),
 		zap.Stringer("address", g.listener.Addr()))
 
+	g.ready.done()
+
 	return g.catch(g.server.Serve(g.listener))
 }
 
 // Stop tries to stop gRPC service.
-func (g *gRPC) Stop(context.Context) {
-	if g.server == nil {
+func (g *gRPC) Stop(ctx context.Context) {
+	if err := g.Shutdown(ctx); err != nil {
 		g.logger.Error("could not stop gRPC server",
 			zap.String("name", g.name),
-			zap.Error(ErrEmptyGRPCServer))
+			zap.Error(err))
+	}
+}
 
-		return
+// Shutdown tries to gracefully stop gRPC service and returns an error
+// if gRPC server is empty.
+func (g *gRPC) Shutdown(context.Context) error {
+	if g.server == nil {
+		return ErrEmptyGRPCServer
 	}
 
 	g.server.GracefulStop()
+
+	return nil
 }
 
 func (g *gRPC) catch(err error) error {
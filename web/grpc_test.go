@@ -12,10 +12,20 @@ import (
 	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/im-kulikov/helium/group"
+	"github.com/im-kulikov/helium/service"
 )
 
 const listenSize = 256 * 1024
 
+// stopper converts service.Service Stop method into group.Shutdown.
+func stopper(svc service.Service) group.Shutdown {
+	return func(ctx context.Context) error {
+		svc.Stop(ctx)
+
+		return nil
+	}
+}
+
 func TestGRPCService(t *testing.T) {
 	t.Run("should set address and network", func(t *testing.T) {
 		lis, err := net.Listen("tcp", "127.0.0.1:0")
@@ -59,13 +69,38 @@ func TestGRPCService(t *testing.T) {
 		require.EqualError(t, log.Err(), ErrEmptyGRPCServer.Error())
 	})
 
-	t.Run("should fail on net.Listen", func(t *testing.T) {
+	t.Run("should fail on net.Listen on start", func(t *testing.T) {
 		srv, err := NewGRPCService(grpc.NewServer(), GRPCListenAddress("test:80"))
-		require.Nil(t, srv)
+		require.NoError(t, err)
+		require.Equal(t, "gRPC(test:80)", srv.Name())
+
+		err = srv.Start(context.Background())
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "listen tcp: lookup test")
 	})
 
+	t.Run("should be ready when serving", func(t *testing.T) {
+		serve, err := NewGRPCService(
+			grpc.NewServer(),
+			GRPCWithLogger(zaptest.NewLogger(t)),
+			GRPCListener(bufconn.Listen(listenSize)))
+		require.NoError(t, err)
+
+		ready, ok := serve.(service.Readiness)
+		require.True(t, ok)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, group.New().
+			Add(serve.Start, stopper(serve)).
+			Add(func(context.Context) error {
+				<-ready.Ready()
+				cancel()
+
+				return nil
+			}, func(context.Context) error { return nil }).
+			Run(ctx))
+	})
+
 	t.Run("should ignore ErrServerStopped", func(t *testing.T) {
 		lis := bufconn.Listen(listenSize)
 		serve, err := NewGRPCService(
@@ -79,6 +114,6 @@ func TestGRPCService(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		require.NoError(t, group.New().Add(serve.Start, serve.Stop).Run(ctx))
+		require.NoError(t, group.New().Add(serve.Start, stopper(serve)).Run(ctx))
 	})
 }
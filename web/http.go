@@ -2,10 +2,13 @@ package web
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -13,6 +16,11 @@ import (
 	"github.com/im-kulikov/helium/service"
 )
 
+var (
+	_ service.Readiness  = (*httpService)(nil)
+	_ service.Shutdowner = (*httpService)(nil)
+)
+
 type (
 	httpService struct {
 		logger *zap.Logger
@@ -22,7 +30,34 @@ type (
 		address    string
 		network    string
 		listener   net.Listener
-		server     *http.Server
+		ready      readiness
+
+		mu        sync.Mutex
+		server    *http.Server
+		retired   map[*http.Server]struct{}
+		tlsConfig *tls.Config
+		nextProto map[string]func(*http.Server, *tls.Conn, http.Handler)
+		stopping  bool
+	}
+
+	// httpTimeouts could be changed while the server is running, see httpService.setTimeouts.
+	httpTimeouts struct {
+		read       time.Duration
+		readHeader time.Duration
+		write      time.Duration
+		idle       time.Duration
+	}
+
+	// interruptListener allows to replace http.Server, that serves the listener: Close interrupts waiting
+	// for connections instead of closing the listener, until the service is stopped.
+	interruptListener struct {
+		net.Listener
+
+		service *httpService
+	}
+
+	deadliner interface {
+		SetDeadline(time.Time) error
 	}
 
 	// HTTPOption interface that allows
@@ -36,6 +71,9 @@ const (
 
 	// ErrEmptyHTTPAddress is raised when passed empty address to NewHTTPService.
 	ErrEmptyHTTPAddress = internal.Error("empty http address")
+
+	// ErrEmptyTLSCertificates is raised when http.Server has TLSConfig without certificates.
+	ErrEmptyTLSCertificates = internal.Error("empty tls certificates")
 )
 
 // HTTPName allows set name for the http-service.
@@ -101,65 +139,229 @@ func NewHTTPService(serve *http.Server, opts ...HTTPOption) (service.Service, er
 		skipErrors: false,
 		server:     serve,
 		network:    "tcp",
+		retired:    make(map[*http.Server]struct{}),
+		tlsConfig:  serve.TLSConfig.Clone(),
+		nextProto:  serve.TLSNextProto,
 	}
 
 	for i := range opts {
 		opts[i](s)
 	}
 
-	if s.listener != nil {
-		return s, nil
+	if s.listener != nil && s.address == "" {
+		s.address = s.listener.Addr().String()
 	}
 
 	if s.address == "" {
 		return nil, ErrEmptyHTTPAddress
 	}
 
-	var err error
-	if s.listener, err = net.Listen(s.network, s.address); err != nil {
-		return nil, s.catch(err)
-	}
-
 	return s, nil
 }
 
-// Name returns name of the service.
+// Name returns name of the service, that could be used in services configs,
+// the address is a part of the name only when the service has no name.
 func (s *httpService) Name() string {
-	return fmt.Sprintf("http(%s) %s", s.name, s.listener.Addr())
+	if s.name == "" {
+		return fmt.Sprintf("http(%s)", s.address)
+	}
+
+	return fmt.Sprintf("http(%s)", s.name)
 }
 
+// Ready returns channel that will be closed when http.Server is serving.
+func (s *httpService) Ready() <-chan struct{} { return s.ready.channel() }
+
 // Start runs http.Server and returns error
 // if something went wrong.
 func (s *httpService) Start(context.Context) error {
-	if s.server == nil {
+	server := s.current()
+	if server == nil {
 		return ErrEmptyHTTPServer
 	}
 
-	switch {
-	case s.server.TLSConfig == nil:
-		return s.catch(s.server.Serve(s.listener))
-	default:
-		// provide cert and key from TLSConfig
-		return s.catch(s.server.ServeTLS(s.listener, "", ""))
+	// cert and key are taken from TLSConfig, ServeTLS fails without them,
+	// so the service is not marked as ready in that case
+	if cfg := server.TLSConfig; cfg != nil && len(cfg.Certificates) == 0 && cfg.GetCertificate == nil && cfg.GetConfigForClient == nil {
+		return ErrEmptyTLSCertificates
+	}
+
+	lis, err := s.listen()
+	if err != nil {
+		return s.catch(err)
+	}
+
+	s.logger.Info("starting http server",
+		zap.String("name", s.name),
+		zap.Stringer("address", lis.Addr()))
+
+	s.ready.done()
+
+	serving := lis
+	if _, ok := lis.(deadliner); ok {
+		serving = interruptListener{Listener: lis, service: s}
+	}
+
+	for {
+		err := serve(server, serving)
+
+		next := s.current()
+		if next == server || !errors.Is(err, http.ErrServerClosed) {
+			return s.catch(err)
+		}
+
+		// the server was replaced by its copy with new timeouts, see setTimeouts
+		if err = lis.(deadliner).SetDeadline(time.Time{}); err != nil {
+			return s.catch(err)
+		}
+
+		server = next
 	}
 }
 
-// Stop tries to stop http.Server and returns error
+// Stop tries to stop http.Server and logs error
 // if something went wrong.
 func (s *httpService) Stop(ctx context.Context) {
-	if s.server == nil {
+	if err := s.Shutdown(ctx); err != nil {
 		s.logger.Error("could not stop http.Server",
 			zap.String("name", s.name),
-			zap.Error(ErrEmptyHTTPServer))
+			zap.Error(err))
+	}
+}
+
+// Shutdown tries to stop http.Server and returns error
+// if something went wrong. Servers replaced by setTimeouts are stopped too.
+func (s *httpService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.server == nil {
+		s.mu.Unlock()
 
-		return
+		return ErrEmptyHTTPServer
 	}
 
-	if err := s.catch(s.server.Shutdown(ctx)); err != nil {
-		s.logger.Error("could not stop http.Server",
-			zap.String("name", s.name),
-			zap.Error(err))
+	s.stopping = true
+
+	servers := make([]*http.Server, 0, len(s.retired)+1)
+	for server := range s.retired {
+		servers = append(servers, server)
 	}
+
+	servers = append(servers, s.server)
+	s.mu.Unlock()
+
+	var err error
+	for _, server := range servers {
+		if stopErr := server.Shutdown(ctx); err == nil {
+			err = stopErr
+		}
+	}
+
+	return s.catch(err)
+}
+
+// setTimeouts replaces the server by its copy with new timeouts: new connections are accepted by the new server,
+// the old one is stopped gracefully. It returns false, when the listener could not be served by other server.
+func (s *httpService) setTimeouts(timeouts httpTimeouts) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopping || s.server == nil {
+		return true
+	}
+
+	if s.listener == nil {
+		// the server is not started yet, so it could be changed in place
+		s.server.ReadTimeout = timeouts.read
+		s.server.ReadHeaderTimeout = timeouts.readHeader
+		s.server.WriteTimeout = timeouts.write
+		s.server.IdleTimeout = timeouts.idle
+
+		return true
+	}
+
+	if _, ok := s.listener.(deadliner); !ok {
+		return false
+	}
+
+	prev := s.server
+	s.retired[prev] = struct{}{}
+	s.server = &http.Server{
+		Handler:           prev.Handler,
+		TLSConfig:         s.tlsConfig.Clone(), // http.Server changes the config while serving
+		ReadTimeout:       timeouts.read,
+		ReadHeaderTimeout: timeouts.readHeader,
+		WriteTimeout:      timeouts.write,
+		IdleTimeout:       timeouts.idle,
+		MaxHeaderBytes:    prev.MaxHeaderBytes,
+		TLSNextProto:      s.nextProto,
+		ConnState:         prev.ConnState,
+		ErrorLog:          prev.ErrorLog,
+		BaseContext:       prev.BaseContext,
+		ConnContext:       prev.ConnContext,
+	}
+
+	go func() {
+		// active connections of the old server are served until they are finished
+		if err := prev.Shutdown(context.Background()); err != nil {
+			s.logger.Warn("could not stop replaced http.Server", zap.String("name", s.name), zap.Error(err))
+		}
+
+		s.mu.Lock()
+		delete(s.retired, prev)
+		s.mu.Unlock()
+	}()
+
+	return true
+}
+
+// listen binds the listener on the first start, so the service fails on start instead of its creation
+// (for example, optional service does not stop other services, when its address is already in use).
+func (s *httpService) listen() (net.Listener, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		return s.listener, nil
+	}
+
+	lis, err := net.Listen(s.network, s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.listener = lis
+
+	return lis, nil
+}
+
+// current returns the server, that serves the listener.
+func (s *httpService) current() *http.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.server
+}
+
+// Close interrupts waiting for connections, so http.Server stops serving the listener,
+// the listener is closed when the service is stopped.
+func (l interruptListener) Close() error {
+	l.service.mu.Lock()
+	stopping := l.service.stopping
+	l.service.mu.Unlock()
+
+	if stopping {
+		return l.Listener.Close()
+	}
+
+	return l.Listener.(deadliner).SetDeadline(time.Now())
+}
+
+func serve(server *http.Server, lis net.Listener) error {
+	if server.TLSConfig == nil {
+		return server.Serve(lis)
+	}
+
+	return server.ServeTLS(lis, "", "")
 }
 
 func (s *httpService) catch(err error) error {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/im-kulikov/helium/group"
 	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/service"
 )
 
 type logger struct {
@@ -105,6 +106,8 @@ func TestHTTPService(t *testing.T) {
 		require.True(t, ok)
 		require.Equal(t, lis.Addr().String(), s.address)
 		require.Equal(t, lis.Addr().Network(), s.network)
+		require.Equal(t, "http(api)", s.Name())
+		require.Nil(t, s.listener, "listener should be bound on start")
 	})
 
 	t.Run("should fail on empty address", func(t *testing.T) {
@@ -130,11 +133,40 @@ func TestHTTPService(t *testing.T) {
 		require.EqualError(t, log.Err(), ErrEmptyHTTPServer.Error())
 	})
 
-	t.Run("should fail on net.Listen", func(t *testing.T) {
+	t.Run("should be ready when serving", func(t *testing.T) {
+		serve, err := NewHTTPService(&http.Server{ReadHeaderTimeout: time.Second},
+			HTTPListener(bufconn.Listen(listenSize)))
+		require.NoError(t, err)
+
+		ready, ok := serve.(service.Readiness)
+		require.True(t, ok)
+
+		top, stop := context.WithCancel(ctx)
+		require.NoError(t, group.New().
+			Add(serve.Start, stopper(serve)).
+			Add(func(context.Context) error {
+				<-ready.Ready()
+				stop()
+
+				return nil
+			}, func(context.Context) error { return nil }).
+			Run(top))
+	})
+
+	t.Run("should fail on net.Listen on start", func(t *testing.T) {
 		srv, err := NewHTTPService(&http.Server{ReadHeaderTimeout: time.Second}, HTTPListenAddress("test:80"))
-		require.Nil(t, srv)
+		require.NoError(t, err)
+		require.Equal(t, "http(test:80)", srv.Name())
+
+		err = srv.Start(ctx)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "listen tcp: lookup test")
+
+		select {
+		case <-srv.(service.Readiness).Ready():
+			t.Fatal("service should not be ready")
+		default:
+		}
 	})
 
 	t.Run("should fail for serve", func(t *testing.T) {
@@ -149,7 +181,7 @@ func TestHTTPService(t *testing.T) {
 
 		top, stop := context.WithCancel(ctx)
 		require.NoError(t, group.New().
-			Add(serve.Start, func(context.Context) { serve.Stop(top) }).
+			Add(serve.Start, func(context.Context) error { return stopper(serve)(top) }).
 			Add(func(ctx context.Context) error {
 				stop()
 
@@ -166,7 +198,7 @@ func TestHTTPService(t *testing.T) {
 				}()
 
 				return nil
-			}, func(context.Context) {}).
+			}, func(context.Context) error { return nil }).
 			Run(top))
 	})
 
@@ -190,12 +222,86 @@ func TestHTTPService(t *testing.T) {
 
 		top, stop := context.WithCancel(ctx)
 		require.NoError(t, group.New().
-			Add(serve.Start, serve.Stop).
+			Add(serve.Start, stopper(serve)).
 			Add(func(context.Context) error {
 				stop()
 
 				return nil
-			}, func(context.Context) {}).
+			}, func(context.Context) error { return nil }).
 			Run(top))
 	})
+
+	t.Run("should not be ready without tls certificates", func(t *testing.T) {
+		s := &http.Server{
+			ReadHeaderTimeout: time.Second,
+			// nolint:gosec
+			TLSConfig: &tls.Config{}, // #nosec G402: TLS MinVersion too low.
+		}
+
+		serve, err := NewHTTPService(s,
+			HTTPListener(bufconn.Listen(listenSize)),
+			HTTPWithLogger(zaptest.NewLogger(t)))
+		require.NoError(t, err)
+
+		require.ErrorIs(t, serve.Start(ctx), ErrEmptyTLSCertificates)
+
+		ready, ok := serve.(service.Readiness)
+		require.True(t, ok)
+
+		select {
+		case <-ready.Ready():
+			t.Fatal("service should not be ready")
+		default:
+		}
+	})
+	t.Run("should replace server with new timeouts", func(t *testing.T) {
+		serve, err := NewHTTPService(&http.Server{
+			ReadHeaderTimeout: time.Second,
+			Handler:           http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+		}, HTTPListenAddress("127.0.0.1:0"), HTTPWithLogger(zaptest.NewLogger(t)))
+		require.NoError(t, err)
+
+		s, ok := serve.(*httpService)
+		require.True(t, ok)
+
+		done := make(chan error, 1)
+		go func() { done <- serve.Start(ctx) }()
+
+		<-s.Ready()
+
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		get := func() {
+			res, errGet := client.Get("http://" + s.listener.Addr().String())
+			require.NoError(t, errGet)
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, http.StatusOK, res.StatusCode)
+		}
+
+		get()
+
+		prev := s.current()
+		require.True(t, s.setTimeouts(httpTimeouts{read: time.Minute, readHeader: time.Second}))
+		require.NotEqual(t, prev, s.current())
+		require.Equal(t, time.Minute, s.current().ReadTimeout)
+
+		get()
+
+		serve.Stop(ctx)
+		require.NoError(t, <-done)
+
+		_, err = net.Dial("tcp", s.listener.Addr().String())
+		require.Error(t, err, "listener should be closed")
+
+		bufServe, err := NewHTTPService(&http.Server{ReadHeaderTimeout: time.Second}, HTTPListener(bufconn.Listen(listenSize)))
+		require.NoError(t, err)
+		require.False(t, bufServe.(*httpService).setTimeouts(httpTimeouts{}), "listener could not be interrupted")
+
+		idle, err := NewHTTPService(&http.Server{ReadHeaderTimeout: time.Second}, HTTPListenAddress("127.0.0.1:0"))
+		require.NoError(t, err)
+
+		server := idle.(*httpService).current()
+		require.True(t, idle.(*httpService).setTimeouts(httpTimeouts{write: time.Minute}))
+		require.Equal(t, server, idle.(*httpService).current(), "server is changed in place before start")
+		require.Equal(t, time.Minute, server.WriteTimeout)
+	})
 }
@@ -25,6 +25,7 @@ type (
 		skipErrors   bool
 		ignoreErrors []error
 		server       Listener
+		ready        readiness
 	}
 
 	// ListenerOption options that allows to change
@@ -36,6 +37,11 @@ type (
 // used in the NewListener function or Listener methods.
 const ErrEmptyListener = internal.Error("empty listener")
 
+var (
+	_ service.Readiness  = (*listener)(nil)
+	_ service.Shutdowner = (*listener)(nil)
+)
+
 // ListenerSkipErrors allows for ignoring all raised errors.
 func ListenerSkipErrors() ListenerOption {
 	return func(l *listener) {
@@ -92,6 +98,17 @@ func NewListener(lis Listener, opts ...ListenerOption) (service.Service, error)
 // Name returns name of the service.
 func (l *listener) Name() string { return l.name }
 
+// Ready returns channel that will be closed when the Listener is up.
+// When the Listener implements service.Readiness its Ready channel is used,
+// otherwise the Listener is considered up when ListenAndServe is called.
+func (l *listener) Ready() <-chan struct{} {
+	if ready, ok := l.server.(service.Readiness); ok {
+		return ready.Ready()
+	}
+
+	return l.ready.channel()
+}
+
 // Start tries to start the Listener and returns an error
 // if the Listener is empty. If something went wrong
 // returns an error.
@@ -100,6 +117,8 @@ func (l *listener) Start(context.Context) error {
 		return l.catch(ErrEmptyListener)
 	}
 
+	l.ready.done()
+
 	return l.catch(l.server.ListenAndServe())
 }
 
@@ -114,13 +133,24 @@ func (l *listener) Stop(ctx context.Context) {
 		return
 	}
 
-	if err := l.catch(l.server.Shutdown(ctx)); err != nil {
+	if err := l.Shutdown(ctx); err != nil {
 		l.logger.Error("could not stop listener",
 			zap.String("name", l.name),
 			zap.Error(err))
 	}
 }
 
+// Shutdown tries to stop the Listener and returns an error
+// if something went wrong. Ignores errors that were passed
+// by options and if used skip errors.
+func (l *listener) Shutdown(ctx context.Context) error {
+	if l.server == nil {
+		return ErrEmptyListener
+	}
+
+	return l.catch(l.server.Shutdown(ctx))
+}
+
 func (l *listener) catch(err error) error {
 	if l.skipErrors {
 		return nil
@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/im-kulikov/helium/internal"
+	"github.com/im-kulikov/helium/service"
 )
 
 type (
@@ -17,6 +18,12 @@ type (
 		startError error
 		stopError  error
 	}
+
+	readyListener struct {
+		fakeListener
+
+		ready chan struct{}
+	}
 )
 
 const (
@@ -35,6 +42,8 @@ func (f fakeListener) Shutdown(context.Context) error {
 	return f.stopError
 }
 
+func (r readyListener) Ready() <-chan struct{} { return r.ready }
+
 func TestListenerService(t *testing.T) {
 	log := zap.NewNop()
 
@@ -102,6 +111,34 @@ func TestListenerService(t *testing.T) {
 		require.Equal(t, l.Result.L, zapcore.ErrorLevel.CapitalString())
 	})
 
+	t.Run("should be ready when started", func(t *testing.T) {
+		serve, err := NewListener(&fakeListener{}, ListenerWithLogger(log))
+		require.NoError(t, err)
+
+		ready, ok := serve.(service.Readiness)
+		require.True(t, ok)
+
+		select {
+		case <-ready.Ready():
+			t.Fatal("listener should not be ready before start")
+		default:
+		}
+
+		require.NoError(t, serve.Start(ctx))
+		<-ready.Ready()
+	})
+
+	t.Run("should use readiness of listener", func(t *testing.T) {
+		lis := readyListener{ready: make(chan struct{})}
+
+		serve, err := NewListener(lis, ListenerWithLogger(log))
+		require.NoError(t, err)
+
+		ready, ok := serve.(service.Readiness)
+		require.True(t, ok)
+		require.Equal(t, (<-chan struct{})(lis.ready), ready.Ready())
+	})
+
 	t.Run("should skip errors", func(t *testing.T) {
 		l := newTestLogger()
 		lis := &fakeListener{stopError: errStopping}
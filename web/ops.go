@@ -2,7 +2,9 @@ package web
 
 import (
 	"context"
+	"encoding/json"
 	"expvar"
+	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"time"
@@ -12,9 +14,11 @@ import (
 	"go.uber.org/dig"
 	"go.uber.org/zap"
 
+	"github.com/im-kulikov/helium/group"
 	"github.com/im-kulikov/helium/internal"
 	"github.com/im-kulikov/helium/module"
 	"github.com/im-kulikov/helium/service"
+	"github.com/im-kulikov/helium/settings"
 )
 
 // ProbeChecker used by ops-server ready and health handler.
@@ -51,6 +55,16 @@ type OpsProbeParams struct {
 
 	HealthProbes []ProbeChecker `group:"health_probes"`
 	ReadyProbes  []ProbeChecker `group:"ready_probes"`
+
+	// Services reports failures of optional services into health probe
+	// and states of all services by /-/services endpoint.
+	Services *service.Monitor `optional:"true"`
+
+	// Tracker allows to export dependency graph by /-/graph endpoint.
+	Tracker *module.Tracker `optional:"true"`
+
+	// BuildInfo allows to export build information by /-/build endpoint.
+	BuildInfo *settings.BuildInfo `optional:"true"`
 }
 
 const (
@@ -58,6 +72,7 @@ const (
 	ErrEmptyConfig = internal.Error("empty configuration")
 
 	opsDefaultName = "ops-server"
+	opsServer      = "ops"
 
 	opsDefaultAddress = ":8081"
 	opsDefaultNetwork = "tcp"
@@ -82,13 +97,17 @@ const (
 	opsPathProfileTrace   = "/debug/pprof/trace"
 	opsPathAppReady       = "/-/ready"
 	opsPathAppHealthy     = "/-/healthy"
+	opsPathAppServices    = "/-/services"
+	opsPathAppGraph       = "/-/graph"
+	opsPathAppBuild       = "/-/build"
 )
 
 var _ = OpsModule
 
 // OpsModule allows import ops http.Server.
 // nolint: gochecknoglobals
-var OpsModule = module.New(NewOpsServer, dig.Group("services")).AppendConstructor(NewOpsConfig)
+var OpsModule = module.Named(module.Info{Name: "web.OpsModule"}, module.New(NewOpsServer, dig.Group("services")).
+	AppendConstructor(NewOpsConfig, addressValidator(opsServer)))
 
 // OpsDefaults allows setting default settings for ops server.
 func OpsDefaults(v *viper.Viper) {
@@ -174,7 +193,23 @@ func NewOpsServer(cfg *OpsConfig, probe OpsProbeParams) (service.Service, error)
 		mux.HandleFunc(opsPathProfileTrace, pprof.Trace)
 	}
 
+	if probe.Tracker != nil {
+		mux.HandleFunc(opsPathAppGraph, dependencyGraph(probe.Tracker))
+	}
+
+	if probe.BuildInfo != nil {
+		mux.HandleFunc(opsPathAppBuild, buildInformation(probe.BuildInfo))
+	}
+
+	if probe.Services != nil {
+		mux.HandleFunc(opsPathAppServices, servicesStates(probe.Services))
+	}
+
 	if !cfg.DisableHealthy {
+		if probe.Services != nil {
+			probe.HealthProbes = append(probe.HealthProbes, probe.Services.Check)
+		}
+
 		mux.HandleFunc(opsPathAppReady, probeChecker(probe.ReadyProbes))
 		mux.HandleFunc(opsPathAppHealthy, probeChecker(probe.HealthProbes))
 	}
@@ -205,3 +240,53 @@ func probeChecker(probes []ProbeChecker) http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+// servicesStates returns states of all services as JSON.
+func servicesStates(monitor *service.Monitor) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		states := monitor.States()
+		if states == nil {
+			states = []group.ServiceState{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(states); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// buildInformation returns build information of the application as JSON.
+func buildInformation(info *settings.BuildInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(info); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// dependencyGraph returns the dependency graph in JSON format or in DOT format (`?format=dot`).
+func dependencyGraph(tracker *module.Tracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		switch format {
+		case "", module.GraphJSON:
+			format = module.GraphJSON
+
+			w.Header().Set("Content-Type", "application/json")
+		case module.GraphDOT:
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+		default:
+			http.Error(w, fmt.Sprintf("%v: %q", module.ErrUnknownGraphFormat, format), http.StatusBadRequest)
+
+			return
+		}
+
+		if err := tracker.Graph().Write(w, format); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package web
 
 import (
 	"context"
+	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,7 +12,13 @@ import (
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
 	"go.uber.org/zap"
+
+	"github.com/im-kulikov/helium/group"
+	"github.com/im-kulikov/helium/module"
+	"github.com/im-kulikov/helium/service"
+	"github.com/im-kulikov/helium/settings"
 )
 
 func TestOpsDefaults(t *testing.T) {
@@ -215,3 +223,165 @@ func TestNewOpsServer(t *testing.T) {
 		})
 	}
 }
+
+func TestOpsServicesProbe(t *testing.T) {
+	monitor := service.NewMonitor()
+
+	handler := newTestOpsHandler(t, OpsConfig{}, OpsProbeParams{Services: monitor})
+
+	{ // all services are healthy
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, opsPathAppHealthy, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	monitor.Fail("optional", ErrEmptyListener)
+
+	{ // optional service failed
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, opsPathAppHealthy, nil))
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.Contains(t, rec.Body.String(), ErrEmptyListener.Error())
+	}
+
+	{ // ready probe should not be affected
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, opsPathAppReady, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+}
+
+func TestOpsServicesStates(t *testing.T) {
+	v := viper.New()
+	OpsDefaults(v)
+	v.Set(cfgOpsAddress, "127.0.0.1:0")
+
+	di := dig.New()
+	require.NoError(t, module.Provide(di, module.Combine(
+		module.Module{
+			{Constructor: func() *viper.Viper { return v }},
+			{Constructor: zap.NewNop},
+		},
+		service.Module,
+		OpsModule)))
+
+	require.NoError(t, di.Invoke(func(grp service.Group, monitor *service.Monitor) {
+		require.Len(t, grp.States(), 1)
+
+		// states of services are served even when healthy probes are disabled
+		handler := newTestOpsHandler(t, OpsConfig{DisableHealthy: true}, OpsProbeParams{Services: monitor})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, opsPathAppHealthy, nil))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, opsPathAppServices, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var states []map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &states))
+		require.Len(t, states, 1)
+		require.Equal(t, grp.States()[0].Name, states[0]["name"])
+		require.Equal(t, string(group.StateCreated), states[0]["state"])
+	}))
+}
+
+func TestOpsOptionalServer(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, busy.Close()) }()
+
+	v := viper.New()
+	OpsDefaults(v)
+	v.Set(cfgOpsAddress, busy.Addr().String())
+	v.Set(service.ServicesParam, []map[string]interface{}{{"name": "http(" + opsDefaultName + ")", "optional": true}})
+
+	di := dig.New()
+	require.NoError(t, module.Provide(di, module.Combine(
+		module.Module{
+			{Constructor: func() *viper.Viper { return v }},
+			{Constructor: zap.NewNop},
+		},
+		service.Module,
+		OpsModule)))
+
+	// ops server binds its address on start, so the optional server does not fail creation of services
+	require.NoError(t, di.Invoke(func(grp service.Group, monitor *service.Monitor) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+
+		go func() { done <- grp.Run(ctx) }()
+
+		require.Eventually(t, func() bool {
+			return monitor.Check(ctx) != nil
+		}, time.Second, time.Millisecond)
+		require.Contains(t, monitor.Check(ctx).Error(), "http("+opsDefaultName+")")
+
+		cancel()
+		require.NoError(t, <-done)
+	}))
+}
+
+func TestOpsDependencyGraph(t *testing.T) {
+	tracker := module.NewTracker()
+	require.NoError(t, tracker.Provide(dig.New(), module.New(zap.NewNop)))
+
+	// dependency graph does not depend on profiling
+	handler := newTestOpsHandler(t, OpsConfig{DisableProfile: true}, OpsProbeParams{Tracker: tracker})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	rec := serve(opsPathAppGraph)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var graph module.Graph
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &graph))
+	require.Len(t, graph.Nodes, 1)
+	require.Equal(t, "go.uber.org/zap", graph.Nodes[0].Module)
+
+	rec = serve(opsPathAppGraph + "?format=dot")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "digraph {")
+
+	rec = serve(opsPathAppGraph + "?format=svg")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestOpsBuildInfo(t *testing.T) {
+	info := &settings.BuildInfo{Name: "app", Version: "v1.0.0", Revision: "abc", GoVersion: "go1.19"}
+
+	handler := newTestOpsHandler(t, OpsConfig{}, OpsProbeParams{BuildInfo: info})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, opsPathAppBuild, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"name":"app","version":"v1.0.0","revision":"abc","dirty":false,"go_version":"go1.19"}`,
+		rec.Body.String())
+}
+
+// newTestOpsHandler creates ops server on random port and returns its handler.
+func newTestOpsHandler(t *testing.T, cfg OpsConfig, probe OpsProbeParams) http.Handler {
+	t.Helper()
+
+	cfg.HTTPConfig = HTTPConfig{
+		Logger:  zap.NewNop(),
+		Name:    opsDefaultName,
+		Address: "127.0.0.1:0",
+		Network: opsDefaultNetwork,
+	}
+
+	svc, err := NewOpsServer(&cfg, probe)
+	require.NoError(t, err)
+
+	return svc.(*httpService).server.Handler
+}
@@ -0,0 +1,41 @@
+package web
+
+import "sync"
+
+// readiness allows services to signal that they are up.
+// Servers signal it right before serving: listener is already bound,
+// so connections will be accepted right after Serve is called.
+// Zero value is ready to use.
+type readiness struct {
+	mu     sync.Mutex
+	ch     chan struct{}
+	closed bool
+}
+
+// channel returns channel that will be closed when service is up.
+func (r *readiness) channel() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ch == nil {
+		r.ch = make(chan struct{})
+	}
+
+	return r.ch
+}
+
+// done marks service as up, it is safe to call it multiple times.
+func (r *readiness) done() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ch == nil {
+		r.ch = make(chan struct{})
+	}
+
+	if !r.closed {
+		close(r.ch)
+
+		r.closed = true
+	}
+}
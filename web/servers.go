@@ -13,6 +13,7 @@ import (
 	"github.com/im-kulikov/helium/internal"
 	"github.com/im-kulikov/helium/module"
 	"github.com/im-kulikov/helium/service"
+	"github.com/im-kulikov/helium/settings"
 )
 
 type (
@@ -43,6 +44,9 @@ type (
 		dig.Out
 
 		Server service.Service `group:"services"`
+
+		// Subscriber applies new timeouts of http server, when config was reloaded (see settings.Reloader).
+		Subscriber settings.Subscriber `group:"config_subscribers"`
 	}
 
 	grpcParams struct {
@@ -74,13 +78,15 @@ var (
 		APIModule,
 	)
 
-	// APIModule defines API server module.
+	// APIModule defines API server module, it is not provided when `api.disabled` is set.
 	// nolint:gochecknoglobals
-	APIModule = module.New(NewAPIServer)
+	APIModule = module.Named(module.Info{Name: "web.APIModule"}, module.Unless(apiServer+".disabled",
+		module.New(NewAPIServer).AppendConstructor(addressValidator(apiServer))))
 
-	// DefaultGRPCModule defines default gRPC server module.
+	// DefaultGRPCModule defines default gRPC server module, it is not provided when `grpc.disabled` is set.
 	// nolint:gochecknoglobals
-	DefaultGRPCModule = module.New(newDefaultGRPCServer)
+	DefaultGRPCModule = module.Named(module.Info{Name: "web.DefaultGRPCModule"}, module.Unless(gRPCServer+".disabled",
+		module.New(newDefaultGRPCServer).AppendConstructor(addressValidator(gRPCServer))))
 )
 
 // NewAPIServer creates api server by http.Handler from DI container.
@@ -112,6 +118,7 @@ func newDefaultGRPCServer(p grpcParams) (ServerResult, error) {
 
 		return ServerResult{}, nil
 	case p.Viper.GetBool(p.Key + ".disabled"):
+		// DefaultGRPCModule checks only `grpc.disabled`, custom keys (see grpcParams.Key) are checked here
 		p.Logger.Info("Server disabled",
 			zap.String("name", p.Name))
 
@@ -169,6 +176,7 @@ func NewHTTPServer(p HTTPParams) (ServerResult, error) {
 
 		return ServerResult{}, nil
 	case p.Config.GetBool(p.Key + ".disabled"):
+		// APIModule checks only `api.disabled`, servers with custom keys are not wrapped by module conditions
 		p.Logger.Info("Server disabled", zap.String("name", p.Key))
 
 		return ServerResult{}, nil
@@ -194,21 +202,13 @@ func NewHTTPServer(p HTTPParams) (ServerResult, error) {
 		options = append(options, HTTPSkipErrors())
 	}
 
-	hServer := &http.Server{Handler: p.Handler, ReadHeaderTimeout: time.Second}
-	if p.Config.IsSet(p.Key + ".read_timeout") {
-		hServer.ReadTimeout = p.Config.GetDuration(p.Key + ".read_timeout")
-	}
-
-	if p.Config.IsSet(p.Key + ".read_header_timeout") {
-		hServer.ReadHeaderTimeout = p.Config.GetDuration(p.Key + ".read_header_timeout")
-	}
-
-	if p.Config.IsSet(p.Key + ".write_timeout") {
-		hServer.WriteTimeout = p.Config.GetDuration(p.Key + ".write_timeout")
-	}
-
-	if p.Config.IsSet(p.Key + ".idle_timeout") {
-		hServer.IdleTimeout = p.Config.GetDuration(p.Key + ".idle_timeout")
+	timeouts := serverTimeouts(p.Config, p.Key)
+	hServer := &http.Server{
+		Handler:           p.Handler,
+		ReadTimeout:       timeouts.read,
+		ReadHeaderTimeout: timeouts.readHeader,
+		WriteTimeout:      timeouts.write,
+		IdleTimeout:       timeouts.idle,
 	}
 
 	if p.Config.IsSet(p.Key + ".max_header_bytes") {
@@ -226,5 +226,42 @@ func NewHTTPServer(p HTTPParams) (ServerResult, error) {
 
 	p.Logger.Info("creating http server", zap.String("name", p.Name), zap.String("address", address))
 
-	return ServerResult{Server: serve}, nil
+	return ServerResult{Server: serve, Subscriber: timeoutsSubscriber(p.Key, serve.(*httpService))}, nil
+}
+
+// serverTimeouts returns timeouts of http server by its settings key.
+func serverTimeouts(v *viper.Viper, key string) httpTimeouts {
+	result := httpTimeouts{readHeader: time.Second}
+
+	if v.IsSet(key + ".read_timeout") {
+		result.read = v.GetDuration(key + ".read_timeout")
+	}
+
+	if v.IsSet(key + ".read_header_timeout") {
+		result.readHeader = v.GetDuration(key + ".read_header_timeout")
+	}
+
+	if v.IsSet(key + ".write_timeout") {
+		result.write = v.GetDuration(key + ".write_timeout")
+	}
+
+	if v.IsSet(key + ".idle_timeout") {
+		result.idle = v.GetDuration(key + ".idle_timeout")
+	}
+
+	return result
+}
+
+// timeoutsSubscriber applies new timeouts of http server, when config was reloaded (see settings.Reloader).
+func timeoutsSubscriber(key string, serve *httpService) settings.Subscriber {
+	return func(event settings.Event) {
+		if !event.Changed(key+".read_timeout") && !event.Changed(key+".read_header_timeout") &&
+			!event.Changed(key+".write_timeout") && !event.Changed(key+".idle_timeout") {
+			return
+		}
+
+		if !serve.setTimeouts(serverTimeouts(event.Settings, key)) {
+			serve.logger.Warn("http server should be restarted to apply new timeouts", zap.String("name", key))
+		}
+	}
 }
@@ -26,6 +26,7 @@ import (
 
 	"github.com/im-kulikov/helium/module"
 	"github.com/im-kulikov/helium/service"
+	"github.com/im-kulikov/helium/settings"
 )
 
 type (
@@ -198,6 +199,23 @@ func TestServers(t *testing.T) {
 		is.Nil(serve.Server)
 	})
 
+	t.Run("disabled modules should not be provided", func(t *testing.T) {
+		cfg := viper.New()
+		dic := dig.New()
+		require.NoError(t, dic.Provide(func() *viper.Viper { return cfg }))
+
+		mod, err := module.Enable(dic, APIModule.Append(DefaultGRPCModule))
+		require.NoError(t, err)
+		require.Len(t, mod, 4)
+
+		cfg.Set("api.disabled", true)
+		cfg.Set("grpc.disabled", true)
+
+		mod, err = module.Enable(dic, APIModule.Append(DefaultGRPCModule))
+		require.NoError(t, err)
+		require.Empty(t, mod)
+	})
+
 	t.Run("api should be configured", func(t *testing.T) {
 		is := require.New(t)
 
@@ -225,6 +243,32 @@ func TestServers(t *testing.T) {
 		is.Equal(lis, s.listener)
 	})
 
+	t.Run("should apply new timeouts on config reload", func(t *testing.T) {
+		cfg := viper.New()
+		cfg.Set("reload.address", "127.0.0.1:0")
+
+		serve, err := NewHTTPServer(HTTPParams{
+			Config:  cfg,
+			Logger:  zaptest.NewLogger(t),
+			Key:     "reload",
+			Handler: http.NotFoundHandler(),
+		})
+		require.NoError(t, err)
+
+		s, ok := serve.Server.(*httpService)
+		require.True(t, ok)
+
+		next := viper.New()
+		next.Set("reload.write_timeout", "5s")
+
+		serve.Subscriber(settings.Event{Changes: []settings.Change{{Key: "reload.address"}}, Settings: next})
+		require.Zero(t, s.current().WriteTimeout, "timeouts were not changed")
+
+		serve.Subscriber(settings.Event{Changes: []settings.Change{{Key: "reload.write_timeout", New: "5s"}}, Settings: next})
+		require.Equal(t, 5*time.Second, s.current().WriteTimeout)
+		require.Equal(t, time.Second, s.current().ReadHeaderTimeout)
+	})
+
 	t.Run("check api server", func(t *testing.T) {
 		t.Run("without config", func(t *testing.T) {
 			serve, err := NewAPIServer(APIParams{Config: v, Logger: l})
@@ -359,7 +403,7 @@ func TestServers(t *testing.T) {
 			}()
 
 			close(start)
-			time.Sleep(time.Millisecond * 10)
+			<-p.Service.Ready()
 
 			wg := new(sync.WaitGroup)
 			wg.Add(len(listeners))